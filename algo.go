@@ -3,9 +3,12 @@ package ptpp
 // Levenshtein computes the Levenshtein distance for two words.
 func Levenshtein(v, w string) int {
 	vcs := []rune(v)
-	wcs := []rune(w)
+	return levenshtein(vcs, []rune(w), make([]int, len(vcs)+1))
+}
 
-	d := make([]int, len(vcs)+1)
+// levenshtein is like Levenshtein, but it takes the runes of the words and a
+// buffer of len(vcs)+1 integers, so that it does not allocate.
+func levenshtein(vcs, wcs []rune, d []int) int {
 	for i := range d {
 		d[i] = i
 	}
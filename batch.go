@@ -0,0 +1,62 @@
+package ptpp
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// BatchResult is the result of processing an input of a batch.
+type BatchResult struct {
+
+	// Phrases are the phrases extracted from the input.
+	Phrases []string
+
+	// Err is the error which stopped the processing of the input, if any.
+	Err error
+}
+
+// ProcessBatch processes many inputs concurrently with the given number of
+// workers. If workers is not positive, runtime.GOMAXPROCS(0) workers are used.
+// The results are in the order of the inputs, and an error only affects the
+// result of its own input.
+func (p *Processor) ProcessBatch(inputs []string, workers int) []BatchResult {
+	return p.ProcessBatchContext(context.Background(), inputs, workers)
+}
+
+// ProcessBatchContext is like ProcessBatch, but it stops when ctx is done. The
+// results of the inputs which are not processed hold ctx.Err().
+func (p *Processor) ProcessBatchContext(ctx context.Context, inputs []string, workers int) []BatchResult {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	p.ensureFields()
+
+	results := make([]BatchResult, len(inputs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				phrases, err := p.ProcessContext(ctx, strings.NewReader(inputs[i]))
+				results[i] = BatchResult{Phrases: phrases, Err: err}
+			}
+		}()
+	}
+
+	for i := range inputs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
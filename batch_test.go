@@ -0,0 +1,71 @@
+package ptpp_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessBatch(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{
+		"bass guitar",
+		"spanish rosetta stone",
+	})
+
+	inputs := []string{
+		"electric base guitarr",
+		"spannish rosetta stone",
+		"",
+		"english rosetta stone",
+	}
+
+	for _, workers := range []int{0, 1, 3, 10} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			results := processor.ProcessBatch(inputs, workers)
+			if !Len(t, results, len(inputs)) {
+				return
+			}
+			for i, input := range inputs {
+				want, err := processor.Process(strings.NewReader(input))
+				Equal(t, ptpp.BatchResult{Phrases: want, Err: err}, results[i])
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, result := range processor.ProcessBatchContext(ctx, inputs, 2) {
+		Equal(t, context.Canceled, result.Err)
+	}
+}
+
+func BenchmarkProcessBatch(b *testing.B) {
+	var processor ptpp.Processor
+	processor.Train([]string{
+		"bass guitar",
+		"electric guitar",
+		"spanish rosetta stone",
+		"english rosetta stone",
+	})
+
+	inputs := make([]string, 1000)
+	for i := range inputs {
+		inputs[i] = "electric base guitarr and spannish rosetta ston"
+	}
+
+	for workers := 1; workers <= runtime.GOMAXPROCS(0); workers *= 2 {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				processor.ProcessBatch(inputs, workers)
+			}
+		})
+	}
+}
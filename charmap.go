@@ -1,5 +1,7 @@
 package ptpp
 
+import "strings"
+
 const (
 	englishLetter = iota
 	englishDigit
@@ -181,3 +183,12 @@ func normalize(r rune) rune {
 	}
 	return r
 }
+
+func normalizeWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if isTashkil(r) {
+			return -1
+		}
+		return normalize(r)
+	}, word)
+}
@@ -0,0 +1,579 @@
+package ptpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// The compact model is a single immutable file with the following layout. All
+// integers are little-endian.
+//
+//	header:   magic [8]byte, version uint32, reserved uint32,
+//	          lexicon offset uint64, contexts offset uint64
+//	lexicon:  length count uint32,
+//	          [length count]{length, first word, last word + 1 uint32},
+//	          word table, word counts [word count]uint32
+//	contexts: context table, context ranges [context count + 1]uint32,
+//	          word table, word counts [word count]uint32
+//	table:    string count uint32, offsets [string count + 1]uint32, data
+//
+// The words of the lexicon are sorted by their length and then bytewise, and
+// the contexts and the words of each context are sorted bytewise, so that all
+// lookups are binary searches on the mapped file.
+
+const (
+	compactMagic      = "PTPPCMP\x00"
+	compactVersion    = 1
+	compactHeaderSize = 32
+)
+
+// ErrInvalidCompactModel is returned when opening a file which is not a valid
+// compact model.
+var ErrInvalidCompactModel = errors.New("ptpp: invalid compact model")
+
+// ErrCompactModelClosed is returned when using the components of a closed
+// compact model.
+var ErrCompactModelClosed = errors.New("ptpp: compact model is closed")
+
+// Compile writes the model of a processor into w in the compact format. The
+// processor must use DefaultSpellChecker and DefaultSemanticMatcher, and no
+// language models. As the compact format only holds the lexicon and the
+// contexts, Compile returns an error if the spell-checker has explicit
+// corrections or the Phonetic option, or the processor has protected words or
+// rules, instead of dropping them.
+func Compile(p *Processor, w io.Writer) error {
+	m := p.ensureFields()
+
+	if len(m.languages) > 0 {
+		return fmt.Errorf("ptpp: cannot compile %d language models", len(m.languages))
+	}
+	if n := m.protectedWords.Len(); n > 0 {
+		return fmt.Errorf("ptpp: cannot compile %d protected words", n)
+	}
+	if n := m.rules.Len(); n > 0 {
+		return fmt.Errorf("ptpp: cannot compile %d rules", n)
+	}
+
+	sc, ok := m.spellChecker.(*DefaultSpellChecker)
+	if !ok {
+		return fmt.Errorf("ptpp: cannot compile spell-checker of type %T", m.spellChecker)
+	}
+	if sc.Phonetic {
+		return errors.New("ptpp: cannot compile a phonetic spell-checker")
+	}
+	if n := len(sc.Corrections()); n > 0 {
+		return fmt.Errorf("ptpp: cannot compile %d spell corrections", n)
+	}
+	sm, ok := m.semanticMatcher.(*DefaultSemanticMatcher)
+	if !ok {
+		return fmt.Errorf("ptpp: cannot compile semantic matcher of type %T", m.semanticMatcher)
+	}
+
+	var lexicon, contexts bytes.Buffer
+
+	sc.mutex.RLock()
+	writeCompactLexicon(&lexicon, sc.lexicon)
+	sc.mutex.RUnlock()
+
+	sm.mutex.RLock()
+	writeCompactContexts(&contexts, sm.contexts)
+	sm.mutex.RUnlock()
+
+	header := make([]byte, compactHeaderSize)
+	copy(header, compactMagic)
+	binary.LittleEndian.PutUint32(header[8:], compactVersion)
+	binary.LittleEndian.PutUint64(header[16:], compactHeaderSize)
+	binary.LittleEndian.PutUint64(header[24:], uint64(compactHeaderSize+lexicon.Len()))
+
+	for _, b := range [][]byte{header, lexicon.Bytes(), contexts.Bytes()} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CompileFile converts a model saved by Processor.Save into a compact model
+// file.
+func CompileFile(modelPath, compactPath string) error {
+	var p Processor
+	if err := p.Load(modelPath); err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(path.Dir(compactPath), "temp-*")
+	if err != nil {
+		return err
+	}
+
+	if err := Compile(&p, tempFile); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), compactPath)
+}
+
+func writeUint32(buf *bytes.Buffer, v int) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(v))
+	buf.Write(b[:])
+}
+
+func writeCompactTable(buf *bytes.Buffer, strs []string) {
+	writeUint32(buf, len(strs))
+	offset := 0
+	writeUint32(buf, offset)
+	for _, s := range strs {
+		offset += len(s)
+		writeUint32(buf, offset)
+	}
+	for _, s := range strs {
+		buf.WriteString(s)
+	}
+}
+
+func writeCompactLexicon(buf *bytes.Buffer, lexicon map[int]wordList) {
+	lengths := make([]int, 0, len(lexicon))
+	for length := range lexicon {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+
+	words := []string{}
+	counts := []int{}
+	writeUint32(buf, len(lengths))
+	for _, length := range lengths {
+		list := sortedWords(lexicon[length])
+		writeUint32(buf, length)
+		writeUint32(buf, len(words))
+		writeUint32(buf, len(words)+len(list))
+		for _, w := range list {
+			words = append(words, w)
+			counts = append(counts, lexicon[length].Count(w))
+		}
+	}
+
+	writeCompactTable(buf, words)
+	for _, c := range counts {
+		writeUint32(buf, c)
+	}
+}
+
+func writeCompactContexts(buf *bytes.Buffer, contexts map[string]wordList) {
+	keys := make([]string, 0, len(contexts))
+	for key := range contexts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	words := []string{}
+	counts := []int{}
+	ranges := []int{0}
+	for _, key := range keys {
+		for _, w := range sortedWords(contexts[key]) {
+			words = append(words, w)
+			counts = append(counts, contexts[key].Count(w))
+		}
+		ranges = append(ranges, len(words))
+	}
+
+	writeCompactTable(buf, keys)
+	for _, r := range ranges {
+		writeUint32(buf, r)
+	}
+	writeCompactTable(buf, words)
+	for _, c := range counts {
+		writeUint32(buf, c)
+	}
+}
+
+func sortedWords(list wordList) []string {
+	words := make([]string, 0, len(list))
+	for w := range list {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+type compactTable struct {
+	n       int
+	offsets []byte
+	data    []byte
+}
+
+func (t *compactTable) at(i int) []byte {
+	start := binary.LittleEndian.Uint32(t.offsets[4*i:])
+	end := binary.LittleEndian.Uint32(t.offsets[4*i+4:])
+	return t.data[start:end]
+}
+
+// search finds s in the sorted range [lo, hi) of the table.
+func (t *compactTable) search(lo, hi int, s string) (int, bool) {
+	key := []byte(s)
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(t.at(lo+i), key) >= 0
+	})
+	return i, i < hi && bytes.Equal(t.at(i), key)
+}
+
+type compactArray []byte
+
+func (a compactArray) at(i int) int {
+	return int(binary.LittleEndian.Uint32(a[4*i:]))
+}
+
+// compactReader parses the sections of a compact model with bounds checks.
+type compactReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *compactReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.data) {
+		r.err = ErrInvalidCompactModel
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *compactReader) uint32() int {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(b))
+}
+
+func (r *compactReader) array(n int) compactArray {
+	return compactArray(r.bytes(4 * n))
+}
+
+// ranges reads n+1 non-decreasing indices which are at most limit.
+func (r *compactReader) ranges(n, limit int) compactArray {
+	a := r.array(n + 1)
+	for i := 0; r.err == nil && i <= n; i++ {
+		if a.at(i) > limit || (i > 0 && a.at(i) < a.at(i-1)) {
+			r.err = ErrInvalidCompactModel
+		}
+	}
+	return a
+}
+
+func (r *compactReader) table() compactTable {
+	t := compactTable{n: r.uint32()}
+	if r.err != nil {
+		return t
+	}
+	if t.n > len(r.data) {
+		r.err = ErrInvalidCompactModel
+		return t
+	}
+	t.offsets = r.ranges(t.n, len(r.data))
+	if r.err != nil {
+		return t
+	}
+	t.data = r.bytes(compactArray(t.offsets).at(t.n))
+	return t
+}
+
+// CompactModel is a read-only model in the compact format, which is queried
+// directly from a memory-mapped file. It must be closed after use.
+type CompactModel struct {
+	data   []byte
+	unmap  func() error
+	closed bool
+	mutex  sync.RWMutex
+
+	lengths         map[int][2]int
+	words           compactTable
+	wordCounts      compactArray
+	contexts        compactTable
+	ranges          compactArray
+	successors      compactTable
+	successorCounts compactArray
+}
+
+// OpenCompactModel opens a compact model file created by Compile or
+// CompileFile. On platforms without mmap support, the file is read into
+// memory.
+func OpenCompactModel(filePath string) (*CompactModel, error) {
+	data, unmap, err := mapFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newCompactModel(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	m.unmap = unmap
+
+	return m, nil
+}
+
+// NewCompactModel creates a compact model from the content of a compact model
+// file, e.g. one embedded with go:embed.
+func NewCompactModel(data []byte) (*CompactModel, error) {
+	return newCompactModel(data)
+}
+
+func newCompactModel(data []byte) (*CompactModel, error) {
+	if len(data) < compactHeaderSize || string(data[:8]) != compactMagic {
+		return nil, ErrInvalidCompactModel
+	}
+	if version := int(binary.LittleEndian.Uint32(data[8:])); version != compactVersion {
+		return nil, &FormatVersionError{Version: version}
+	}
+
+	lexiconOffset := binary.LittleEndian.Uint64(data[16:])
+	contextsOffset := binary.LittleEndian.Uint64(data[24:])
+	if lexiconOffset > uint64(len(data)) || contextsOffset > uint64(len(data)) {
+		return nil, ErrInvalidCompactModel
+	}
+
+	m := &CompactModel{data: data, lengths: map[int][2]int{}}
+
+	r := &compactReader{data: data, pos: int(lexiconOffset)}
+	lengths := r.uint32()
+	if lengths > len(data) {
+		return nil, ErrInvalidCompactModel
+	}
+	entries := r.array(3 * lengths)
+	m.words = r.table()
+	m.wordCounts = r.array(m.words.n)
+	for i := 0; r.err == nil && i < lengths; i++ {
+		first, last := entries.at(3*i+1), entries.at(3*i+2)
+		if first > last || last > m.words.n {
+			return nil, ErrInvalidCompactModel
+		}
+		m.lengths[entries.at(3*i)] = [2]int{first, last}
+	}
+
+	r.pos = int(contextsOffset)
+	m.contexts = r.table()
+	m.ranges = r.ranges(m.contexts.n, len(data))
+	m.successors = r.table()
+	m.successorCounts = r.array(m.successors.n)
+	if r.err == nil && m.ranges.at(m.contexts.n) > m.successors.n {
+		return nil, ErrInvalidCompactModel
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return m, nil
+}
+
+// Close releases the mapped file of the model. Close waits for the queries in
+// progress, and the components of the model must not be used afterwards: the
+// processor gets ErrCompactModelClosed from them, and their methods without
+// errors treat every word as unknown.
+func (m *CompactModel) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.closed = true
+	m.data = nil
+	m.lengths = nil
+	m.words = compactTable{}
+	m.wordCounts = compactArray{}
+	m.contexts = compactTable{}
+	m.ranges = compactArray{}
+	m.successors = compactTable{}
+	m.successorCounts = compactArray{}
+	if m.unmap == nil {
+		return nil
+	}
+	unmap := m.unmap
+	m.unmap = nil
+	return unmap()
+}
+
+// SpellChecker returns the read-only spell-checker of the model.
+func (m *CompactModel) SpellChecker() *CompactSpellChecker {
+	return &CompactSpellChecker{model: m}
+}
+
+// SemanticMatcher returns the read-only semantic matcher of the model.
+func (m *CompactModel) SemanticMatcher() *CompactSemanticMatcher {
+	return &CompactSemanticMatcher{model: m}
+}
+
+func (m *CompactModel) count(word string, length int) int {
+	r, ok := m.lengths[length]
+	if !ok {
+		return 0
+	}
+	i, ok := m.words.search(r[0], r[1], word)
+	if !ok {
+		return 0
+	}
+	return m.wordCounts.at(i)
+}
+
+// CompactSpellChecker is a read-only SpellChecker backed by a CompactModel.
+// It gives the same suggestions as the DefaultSpellChecker it was compiled
+// from.
+type CompactSpellChecker struct {
+	model *CompactModel
+}
+
+// Check finds correct spell suggestions for a word.
+func (sc *CompactSpellChecker) Check(word string) []string {
+	scored := sc.CheckScored(word)
+
+	suggestions := make([]string, len(scored))
+	for i, s := range scored {
+		suggestions[i] = s.Word
+	}
+
+	return suggestions
+}
+
+// CheckScored finds correct spell suggestions for a word along with their
+// confidences.
+func (sc *CompactSpellChecker) CheckScored(word string) []Suggestion {
+	sc.model.mutex.RLock()
+	defer sc.model.mutex.RUnlock()
+
+	if sc.model.closed {
+		return []Suggestion{{Word: word}}
+	}
+	return sc.checkScored(word)
+}
+
+// CheckScoredContext is like CheckScored, but it returns ctx.Err(), or
+// ErrCompactModelClosed if the model is closed.
+func (sc *CompactSpellChecker) CheckScoredContext(ctx context.Context, word string) ([]Suggestion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sc.model.mutex.RLock()
+	defer sc.model.mutex.RUnlock()
+
+	if sc.model.closed {
+		return nil, ErrCompactModelClosed
+	}
+	return sc.checkScored(word), nil
+}
+
+func (sc *CompactSpellChecker) checkScored(word string) []Suggestion {
+	length := utf8.RuneCountInString(word)
+	if length < 2 {
+		return []Suggestion{{Word: word, Confidence: 1}}
+	}
+
+	m := sc.model
+	neighbours := wordList{}
+
+	// The words are compared in place, decoding their runes into a buffer,
+	// and only the neighbours are converted to strings.
+	vcs := []rune(word)
+	wcs := make([]rune, 0, length+1)
+	d := make([]int, length+1)
+	for _, l := range []int{length, length - 1, length + 1} {
+		r, ok := m.lengths[l]
+		if l < 2 || !ok {
+			continue
+		}
+		for i := r[0]; i < r[1]; i++ {
+			w := m.words.at(i)
+			wcs = wcs[:0]
+			for b := w; len(b) > 0; {
+				wc, n := utf8.DecodeRune(b)
+				wcs = append(wcs, wc)
+				b = b[n:]
+			}
+			if string(w) != word && levenshtein(vcs, wcs, d) == 1 {
+				neighbours[string(w)] = m.wordCounts.at(i)
+			}
+		}
+	}
+
+	return scoreSuggestions(word, m.count(word, length), neighbours)
+}
+
+// Train does nothing, as the compact model is read-only.
+func (sc *CompactSpellChecker) Train(words []string) {}
+
+// CompactSemanticMatcher is a read-only SemanticMatcher backed by a
+// CompactModel.
+type CompactSemanticMatcher struct {
+	model *CompactModel
+}
+
+// Match finds the best suggestion based on the context.
+func (sm *CompactSemanticMatcher) Match(context string, suggestions []string) (string, bool) {
+	sm.model.mutex.RLock()
+	defer sm.model.mutex.RUnlock()
+
+	if sm.model.closed {
+		return suggestions[0], false
+	}
+	return sm.match(context, suggestions)
+}
+
+// MatchContext is like Match, but it returns ctx.Err(), or
+// ErrCompactModelClosed if the model is closed.
+func (sm *CompactSemanticMatcher) MatchContext(ctx context.Context, context string, suggestions []string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	sm.model.mutex.RLock()
+	defer sm.model.mutex.RUnlock()
+
+	if sm.model.closed {
+		return "", false, ErrCompactModelClosed
+	}
+	best, matched := sm.match(context, suggestions)
+	return best, matched, nil
+}
+
+func (sm *CompactSemanticMatcher) match(context string, suggestions []string) (string, bool) {
+	m := sm.model
+
+	if i, ok := m.contexts.search(0, m.contexts.n, context); ok {
+		lo, hi := m.ranges.at(i), m.ranges.at(i+1)
+		for _, suggestion := range suggestions {
+			if _, ok := m.successors.search(lo, hi, suggestion); ok {
+				return suggestion, true
+			}
+		}
+	}
+
+	return suggestions[0], false
+}
+
+// Train does nothing, as the compact model is read-only.
+func (sm *CompactSemanticMatcher) Train(context, word string) {}
+
+// TrainContext does nothing, as the compact model is read-only.
+func (sm *CompactSemanticMatcher) TrainContext(ctx context.Context, context, word string) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+package ptpp_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCompactModel(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if !NoError(t, err) {
+		return
+	}
+
+	modelPath := path.Join(workingDir, "test-compact.zip")
+	compactPath := path.Join(workingDir, "test-compact.bin")
+	defer os.Remove(modelPath)
+	defer os.Remove(compactPath)
+
+	var processor ptpp.Processor
+	processor.Train([]string{
+		"bass guitar",
+		"bass guitar",
+		"spanish rosetta stone",
+		"quality",
+		"quantity",
+	})
+	if !NoError(t, processor.Save(modelPath)) || !NoError(t, ptpp.CompileFile(modelPath, compactPath)) {
+		return
+	}
+
+	model, err := ptpp.OpenCompactModel(compactPath)
+	if !NoError(t, err) {
+		return
+	}
+	defer model.Close()
+
+	compact := ptpp.Processor{
+		SpellChecker:    model.SpellChecker(),
+		SemanticMatcher: model.SemanticMatcher(),
+	}
+
+	for _, phrase := range []string{
+		"electric base guitarr",
+		"english rosetta stone",
+		"spannish rosetta stone",
+		"quanlity",
+	} {
+		t.Run(phrase, func(t *testing.T) {
+			want, _ := processor.ProcessPhrases(strings.NewReader(phrase))
+			got, err := compact.ProcessPhrases(strings.NewReader(phrase))
+			NoError(t, err)
+			Equal(t, want, got)
+		})
+	}
+
+	var buf bytes.Buffer
+	if NoError(t, ptpp.Compile(&processor, &buf)) {
+		data := buf.Bytes()
+		_, err := ptpp.NewCompactModel(data[:len(data)-3])
+		True(t, errors.Is(err, ptpp.ErrInvalidCompactModel))
+	}
+}
+
+func TestCompactModelClose(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar"})
+
+	var buf bytes.Buffer
+	if !NoError(t, ptpp.Compile(&processor, &buf)) {
+		return
+	}
+	model, err := ptpp.NewCompactModel(buf.Bytes())
+	if !NoError(t, err) {
+		return
+	}
+
+	compact := ptpp.Processor{
+		SpellChecker:    model.SpellChecker(),
+		SemanticMatcher: model.SemanticMatcher(),
+	}
+	NoError(t, model.Close())
+	NoError(t, model.Close())
+
+	_, err = compact.Process(strings.NewReader("bass guitarr"))
+	True(t, errors.Is(err, ptpp.ErrCompactModelClosed))
+	Equal(t, []string{"guitarr"}, model.SpellChecker().Check("guitarr"))
+	best, matched := model.SemanticMatcher().Match("bass", []string{"guitar"})
+	Equal(t, "guitar", best)
+	False(t, matched)
+}
+
+func TestCompileUnsupported(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor func() *ptpp.Processor
+	}{
+		{"corrections", func() *ptpp.Processor {
+			sc := &ptpp.DefaultSpellChecker{}
+			sc.AddCorrection("guitr", "guitar")
+			return &ptpp.Processor{SpellChecker: sc}
+		}},
+		{"phonetic", func() *ptpp.Processor {
+			return &ptpp.Processor{SpellChecker: &ptpp.DefaultSpellChecker{Phonetic: true}}
+		}},
+		{"protected words", func() *ptpp.Processor {
+			ws := &ptpp.WordSet{}
+			ws.Add("ipod")
+			return &ptpp.Processor{ProtectedWords: ws}
+		}},
+		{"rules", func() *ptpp.Processor {
+			rs := &ptpp.RuleSet{}
+			rs.Add("tv", "television")
+			return &ptpp.Processor{Rules: rs}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Error(t, ptpp.Compile(tt.processor(), &buf))
+		})
+	}
+}
+
+func TestCompactSpellCheckerAllocs(t *testing.T) {
+	var processor ptpp.Processor
+	for i := 0; i < 1000; i++ {
+		processor.Train([]string{ptpp.EnglishNumber(int64(i))})
+	}
+
+	var buf bytes.Buffer
+	if !NoError(t, ptpp.Compile(&processor, &buf)) {
+		return
+	}
+	model, err := ptpp.NewCompactModel(buf.Bytes())
+	if !NoError(t, err) {
+		return
+	}
+	defer model.Close()
+
+	sc := model.SpellChecker()
+	allocs := testing.AllocsPerRun(10, func() {
+		sc.Check("hundrd")
+	})
+	Less(t, allocs, 20.0)
+}
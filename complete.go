@@ -0,0 +1,258 @@
+package ptpp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// typoPenalty is the factor applied to the score of a completion for each
+	// typo in its prefix.
+	typoPenalty = 0.1
+
+	// maxNextWords is the maximum number of next words of a completion.
+	maxNextWords = 3
+)
+
+// Completion is a completion of the word being typed.
+type Completion struct {
+
+	// Word is the completed word.
+	Word string
+
+	// Next are the words which are likely to follow Word, most likely first.
+	Next []string
+
+	// Score is the relative score of the completion. Completions which
+	// follow the context rank higher than the ones which do not, and the more
+	// frequent words rank higher among them. Typos in the prefix lower the
+	// score.
+	Score float64
+}
+
+// Completer provides autocompletion of search queries using a trie of the
+// vocabulary of a processor. It uses a snapshot of the model, so it must be
+// created again to reflect further training.
+type Completer struct {
+
+	// MaxTypos is the maximum number of typos in a prefix. At most one typo is
+	// tolerated for each three letters of the prefix.
+	MaxTypos int
+
+	// MaxResults is the maximum number of completions. If this field is not
+	// positive, all completions are returned.
+	MaxResults int
+
+	root     *trieNode
+	total    int
+	contexts map[string]wordList
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	word     string
+	count    int
+}
+
+// NewCompleter creates a Completer from the model of a processor, which must
+// use DefaultSpellChecker, also for its language models. The next words are
+// predicted by the models which use DefaultSemanticMatcher.
+func NewCompleter(p *Processor) (*Completer, error) {
+	all := p.ensureFields().all()
+	for _, m := range all {
+		if _, ok := m.spellChecker.(*DefaultSpellChecker); !ok {
+			return nil, fmt.Errorf("ptpp: cannot complete with spell-checker of type %T", m.spellChecker)
+		}
+	}
+
+	c := &Completer{
+		MaxTypos:   1,
+		MaxResults: 10,
+		root:       &trieNode{},
+		contexts:   map[string]wordList{},
+	}
+
+	for _, m := range all {
+		sc := m.spellChecker.(*DefaultSpellChecker)
+		sc.mutex.RLock()
+		for _, list := range sc.lexicon {
+			for word, count := range list {
+				c.insert(word, count)
+			}
+		}
+		sc.mutex.RUnlock()
+
+		if sm, ok := m.semanticMatcher.(*DefaultSemanticMatcher); ok {
+			sm.mutex.RLock()
+			for context, list := range sm.contexts {
+				if _, ok := c.contexts[context]; !ok {
+					c.contexts[context] = wordList{}
+				}
+				c.contexts[context].merge(list)
+			}
+			sm.mutex.RUnlock()
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Completer) insert(word string, count int) {
+	node := c.root
+	for _, r := range word {
+		if node.children == nil {
+			node.children = map[rune]*trieNode{}
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+
+	node.word = word
+	node.count += count
+	c.total += count
+}
+
+// Complete finds the completions of the word being typed, given its prefix
+// and the preceding text as the context. If the prefix has more than one word,
+// its last word is completed and the previous one is the context. If the
+// prefix is empty, the words likely to follow the context are returned.
+func (c *Completer) Complete(prefix, context string) []Completion {
+	prefixWords, _ := readWords(strings.NewReader(prefix))
+	contextWords, _ := readWords(strings.NewReader(context))
+	contextWords = append(contextWords, prefixWords...)
+
+	word := ""
+	if len(prefixWords) > 0 && endsInWord(prefix) {
+		word = prefixWords[len(prefixWords)-1]
+		contextWords = contextWords[:len(contextWords)-1]
+	}
+
+	var following wordList
+	if len(contextWords) > 0 {
+		following = c.contexts[contextWords[len(contextWords)-1]]
+	}
+
+	completions := []Completion{}
+	if word == "" {
+		for w, count := range following {
+			completions = append(completions, Completion{Word: w, Score: float64(count)})
+		}
+		return c.rank(completions)
+	}
+
+	maxTypos := utf8.RuneCountInString(word) / 3
+	if maxTypos > c.MaxTypos {
+		maxTypos = c.MaxTypos
+	}
+
+	query := []rune(word)
+	row := make([]int, len(query)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	c.search(c.root, query, row, len(query), maxTypos, func(node *trieNode, typos int) {
+		score := float64(following.Count(node.word)) + float64(node.count)/float64(c.total+1)
+		for i := 0; i < typos; i++ {
+			score *= typoPenalty
+		}
+		completions = append(completions, Completion{Word: node.word, Score: score})
+	})
+
+	return c.rank(completions)
+}
+
+// search walks the trie and finds the words with a prefix within maxTypos
+// edits of query. row is the row of the edit distances between query and the
+// path of node, and matched is the least distance of a prefix of the path.
+func (c *Completer) search(node *trieNode, query []rune, row []int, matched, maxTypos int, fn func(*trieNode, int)) {
+	if row[len(query)] < matched {
+		matched = row[len(query)]
+	}
+	if node.word != "" && matched <= maxTypos {
+		fn(node, matched)
+	}
+
+	for r, child := range node.children {
+		next := make([]int, len(row))
+		next[0] = row[0] + 1
+		least := next[0]
+		for j := 1; j < len(row); j++ {
+			cost := 1
+			if query[j-1] == r {
+				cost = 0
+			}
+			next[j] = min3(row[j]+1, next[j-1]+1, row[j-1]+cost)
+			if next[j] < least {
+				least = next[j]
+			}
+		}
+
+		if least <= maxTypos || matched <= maxTypos {
+			c.search(child, query, next, matched, maxTypos, fn)
+		}
+	}
+}
+
+// rank sorts the completions by their scores and keeps the best ones. The next
+// words are only found for the kept completions, as sorting the successors of
+// every candidate is costly for short prefixes.
+func (c *Completer) rank(completions []Completion) []Completion {
+	sort.Slice(completions, func(i, j int) bool {
+		if completions[i].Score != completions[j].Score {
+			return completions[i].Score > completions[j].Score
+		}
+		return completions[i].Word < completions[j].Word
+	})
+
+	if c.MaxResults > 0 && len(completions) > c.MaxResults {
+		completions = completions[:c.MaxResults]
+	}
+
+	for i := range completions {
+		next := sortedByCount(c.contexts[completions[i].Word])
+		if len(next) > maxNextWords {
+			next = next[:maxNextWords]
+		}
+		completions[i].Next = next
+	}
+
+	return completions
+}
+
+// sortedByCount returns the words of a list, the most frequent first.
+func sortedByCount(list wordList) []string {
+	words := make([]string, 0, len(list))
+	for word := range list {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if list[words[i]] != list[words[j]] {
+			return list[words[i]] > list[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	return words
+}
+
+// endsInWord checks whether the last word of s is not yet terminated.
+func endsInWord(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return s != "" && isWordRune(r)
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
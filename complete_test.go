@@ -0,0 +1,66 @@
+package ptpp_test
+
+import (
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCompleter(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{
+		"bass guitar",
+		"bass guitar",
+		"bass drum",
+		"electric bass",
+		"base camp",
+		"basket",
+		"basket",
+		"basket",
+		"basket",
+		"basket",
+	})
+
+	completer, err := ptpp.NewCompleter(&processor)
+	if !NoError(t, err) {
+		return
+	}
+
+	words := func(completions []ptpp.Completion) []string {
+		result := []string{}
+		for _, c := range completions {
+			result = append(result, c.Word)
+		}
+		return result
+	}
+
+	tests := []struct {
+		prefix  string
+		context string
+		want    []string
+	}{
+		{"bas", "", []string{"basket", "bass", "base"}},
+		{"bas", "electric", []string{"bass", "basket", "base"}},
+		{"electric bas", "", []string{"bass", "basket", "base"}},
+		{"vass", "", []string{"bass"}},
+		{"bass ", "", []string{"guitar", "drum"}},
+		{"", "bass", []string{"guitar", "drum"}},
+		{"x", "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix+"|"+tt.context, func(t *testing.T) {
+			Equal(t, tt.want, words(completer.Complete(tt.prefix, tt.context)))
+		})
+	}
+
+	completions := completer.Complete("bas", "electric")
+	Equal(t, []string{"guitar", "drum"}, completions[0].Next)
+
+	completer.MaxResults = 1
+	Equal(t, []string{"basket"}, words(completer.Complete("bas", "")))
+
+	_, err = ptpp.NewCompleter(&ptpp.Processor{SpellChecker: staticSpellChecker{}})
+	Error(t, err)
+}
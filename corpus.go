@@ -0,0 +1,239 @@
+package ptpp
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// CorpusFormat is the format of a training corpus.
+type CorpusFormat int
+
+const (
+	// TextCorpus is a plain text corpus. Its paragraphs are separated by
+	// blank lines.
+	TextCorpus CorpusFormat = iota
+
+	// JSONLCorpus is a corpus of JSON objects, one per line, whose text is in
+	// string fields.
+	JSONLCorpus
+
+	// CSVCorpus is a CSV corpus with a header row, whose text is in columns.
+	CSVCorpus
+)
+
+// CorpusOptions are the options of training with a corpus.
+type CorpusOptions struct {
+
+	// Format is the format of the corpus.
+	Format CorpusFormat
+
+	// Fields are the names of the JSONL fields or the CSV columns which hold
+	// the text. If this field is empty, the "text" field or column is used.
+	Fields []string
+
+	// MinFrequency is the minimum number of occurrences of a word, or a pair
+	// of consecutive words, in the corpus for being added to the model. It
+	// requires DefaultSpellChecker and DefaultSemanticMatcher, also for the
+	// language models.
+	MinFrequency int
+}
+
+// TrainCorpus trains the preprocessing model with a corpus of raw documents.
+// The text of the documents is split into sentences, and each sentence is
+// trained as a phrase. The corpus is trained into a separate model, which is
+// merged into the model at the end, so the model is not changed if the corpus
+// cannot be read or parsed.
+//
+// This requires DefaultSpellChecker and DefaultSemanticMatcher, also for the
+// language models. Other components are trained as the corpus is read, so the
+// sentences read before an error stay trained, and they cannot be filtered by
+// MinFrequency.
+func (p *Processor) TrainCorpus(r io.Reader, opts CorpusOptions) error {
+	m := p.ensureFields()
+
+	target := p
+	if isMergeable(m) {
+		target = p.newShard(m)
+	} else if opts.MinFrequency > 1 {
+		return fmt.Errorf("ptpp: cannot filter by frequency with components of types %T and %T", m.spellChecker, m.semanticMatcher)
+	}
+
+	const batchSize = 1024
+
+	batch := make([]string, 0, batchSize)
+	err := readCorpus(r, opts, func(text string) {
+		for _, sentence := range splitSentences(text) {
+			batch = append(batch, sentence)
+			if len(batch) == batchSize {
+				target.Train(batch)
+				batch = batch[:0]
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		target.Train(batch)
+	}
+
+	if target == p {
+		return nil
+	}
+
+	if opts.MinFrequency > 1 {
+		for _, lm := range target.ensureFields().all() {
+			lm.spellChecker.(*DefaultSpellChecker).Prune(opts.MinFrequency)
+			lm.semanticMatcher.(*DefaultSemanticMatcher).Prune(opts.MinFrequency)
+		}
+	}
+
+	return p.Merge(target)
+}
+
+// readCorpus reads the documents of a corpus and passes their text to fn.
+func readCorpus(r io.Reader, opts CorpusOptions, fn func(text string)) error {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = []string{"text"}
+	}
+
+	switch opts.Format {
+	case TextCorpus:
+		return readTextCorpus(r, fn)
+	case JSONLCorpus:
+		return readJSONLCorpus(r, fields, fn)
+	case CSVCorpus:
+		return readCSVCorpus(r, fields, fn)
+	}
+
+	return fmt.Errorf("ptpp: unknown corpus format %d", opts.Format)
+}
+
+func readTextCorpus(r io.Reader, fn func(text string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	paragraph := strings.Builder{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			paragraph.WriteString(line)
+			paragraph.WriteByte(' ')
+			continue
+		}
+		if paragraph.Len() > 0 {
+			fn(paragraph.String())
+			paragraph.Reset()
+		}
+	}
+
+	if paragraph.Len() > 0 {
+		fn(paragraph.String())
+	}
+
+	return scanner.Err()
+}
+
+func readJSONLCorpus(r io.Reader, fields []string, fn func(text string)) error {
+	dec := json.NewDecoder(r)
+	for {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		for _, field := range fields {
+			if text, ok := doc[field].(string); ok {
+				fn(text)
+			}
+		}
+	}
+}
+
+func readCSVCorpus(r io.Reader, fields []string, fn func(text string)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	columns := make([]int, len(fields))
+	for i, field := range fields {
+		columns[i] = -1
+		for j, name := range header {
+			if strings.TrimSpace(name) == field {
+				columns[i] = j
+				break
+			}
+		}
+		if columns[i] < 0 {
+			return fmt.Errorf("ptpp: corpus column %q is missing", field)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		for _, column := range columns {
+			if column < len(record) {
+				fn(record[column])
+			}
+		}
+	}
+}
+
+// splitSentences splits text into sentences at line breaks and sentence
+// terminators. A Latin terminator must be followed by a white space, so that
+// numbers and URLs such as "3.5" and "example.com" are not split. An
+// abbreviation followed by a white space, such as "Dr. Smith", is split.
+func splitSentences(text string) []string {
+	sentences := []string{}
+	runes := []rune(text)
+
+	start := 0
+	for i, r := range runes {
+		end := false
+		switch r {
+		case '\n', '\r', '؟', '؛', '…', '۔':
+			end = true
+		case '.', '!', '?', ';':
+			end = i == len(runes)-1 || unicode.IsSpace(runes[i+1])
+		}
+		if !end {
+			continue
+		}
+
+		if sentence := strings.TrimSpace(string(runes[start:i])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+
+	return sentences
+}
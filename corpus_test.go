@@ -0,0 +1,71 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorTrainCorpus(t *testing.T) {
+	tests := []struct {
+		name   string
+		corpus string
+		opts   ptpp.CorpusOptions
+	}{
+		{
+			name:   "text",
+			corpus: "I play the bass guitar. Drum\nsolo!\n\nThe spanish rosetta stone?",
+			opts:   ptpp.CorpusOptions{Format: ptpp.TextCorpus},
+		},
+		{
+			name: "jsonl",
+			corpus: `{"id": 1, "text": "I play the bass guitar. Drum solo!"}
+{"id": 2, "text": "The spanish rosetta stone?", "title": "guitar drum"}`,
+			opts: ptpp.CorpusOptions{Format: ptpp.JSONLCorpus},
+		},
+		{
+			name:   "csv",
+			corpus: "id,body\n1,\"I play the bass guitar. Drum solo!\"\n2,The spanish rosetta stone?\n",
+			opts:   ptpp.CorpusOptions{Format: ptpp.CSVCorpus, Fields: []string{"body"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var processor ptpp.Processor
+			if !NoError(t, processor.TrainCorpus(strings.NewReader(tt.corpus), tt.opts)) {
+				return
+			}
+
+			Equal(t, int64(3), processor.Training().Phrases)
+
+			got, _ := processor.Process(strings.NewReader("base guitarr drum spannish rosetta stone"))
+			Equal(t, []string{"bass guitar", "drum", "spanish rosetta stone"}, got)
+		})
+	}
+
+	var processor ptpp.Processor
+	err := processor.TrainCorpus(strings.NewReader("id,text\n"), ptpp.CorpusOptions{Format: ptpp.CSVCorpus, Fields: []string{"body"}})
+	Error(t, err)
+
+	corpus := "bass guitar\nbass guitar\nbass guittar\nbass drum\n"
+	NoError(t, processor.TrainCorpus(strings.NewReader(corpus), ptpp.CorpusOptions{MinFrequency: 2}))
+
+	sc := processor.SpellChecker.(*ptpp.DefaultSpellChecker)
+	Equal(t, []string{"guitar"}, sc.Check("guittar"))
+	Equal(t, []ptpp.Suggestion{{Word: "drum"}}, sc.CheckScored("drum"))
+
+	got, _ := processor.Process(strings.NewReader("bass drum"))
+	Equal(t, []string{"bass", "drum"}, got)
+
+	var partial ptpp.Processor
+	jsonl := strings.Repeat(`{"text": "bass guitar"}`+"\n", 2000) + `{"text": `
+	Error(t, partial.TrainCorpus(strings.NewReader(jsonl), ptpp.CorpusOptions{Format: ptpp.JSONLCorpus}))
+	Equal(t, int64(0), partial.Training().Phrases)
+	Equal(t, []string{"gitar"}, partial.SpellChecker.Check("gitar"))
+
+	custom := ptpp.Processor{SpellChecker: staticSpellChecker{}}
+	Error(t, custom.TrainCorpus(strings.NewReader(corpus), ptpp.CorpusOptions{MinFrequency: 2}))
+}
@@ -0,0 +1,385 @@
+package ptpp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Calendar is a calendar system.
+type Calendar int
+
+const (
+	// Jalali is the Solar Hijri calendar, the official calendar of Iran.
+	Jalali Calendar = iota
+
+	// Gregorian is the Gregorian calendar.
+	Gregorian
+
+	// Hijri is the Lunar Hijri calendar. Conversions use the tabular Islamic
+	// calendar, which may differ by a day or two from the observed one.
+	Hijri
+)
+
+// Date is a day in a calendar system.
+type Date struct {
+	Calendar Calendar
+	Year     int
+	Month    int
+	Day      int
+}
+
+// String returns the date in the canonical YYYY-MM-DD form.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Valid reports whether the date exists in its calendar.
+func (d Date) Valid() bool {
+	if d.Year < 1 || d.Month < 1 || d.Month > 12 || d.Day < 1 {
+		return false
+	}
+	return d.Day <= daysInMonth(d.Calendar, d.Year, d.Month)
+}
+
+// In converts the date into another calendar.
+func (d Date) In(c Calendar) Date {
+	if d.Calendar == c {
+		return d
+	}
+	return fromJDN(c, d.jdn())
+}
+
+func (d Date) jdn() int {
+	switch d.Calendar {
+	case Jalali:
+		return jalaliToJDN(d.Year, d.Month, d.Day)
+	case Hijri:
+		return hijriToJDN(d.Year, d.Month, d.Day)
+	default:
+		return gregorianToJDN(d.Year, d.Month, d.Day)
+	}
+}
+
+func fromJDN(c Calendar, jdn int) Date {
+	var y, m, d int
+	switch c {
+	case Jalali:
+		y, m, d = jdnToJalali(jdn)
+	case Hijri:
+		y, m, d = jdnToHijri(jdn)
+	default:
+		y, m, d = jdnToGregorian(jdn)
+	}
+	return Date{Calendar: c, Year: y, Month: m, Day: d}
+}
+
+func daysInMonth(c Calendar, year, month int) int {
+	switch c {
+	case Jalali:
+		switch {
+		case month <= 6:
+			return 31
+		case month <= 11:
+			return 30
+		case jalaliLeap(year):
+			return 30
+		default:
+			return 29
+		}
+	case Hijri:
+		switch {
+		case month%2 == 1:
+			return 30
+		case month == 12 && (14+11*year)%30 < 11:
+			return 30
+		default:
+			return 29
+		}
+	default:
+		switch month {
+		case 2:
+			if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+				return 29
+			}
+			return 28
+		case 4, 6, 9, 11:
+			return 30
+		default:
+			return 31
+		}
+	}
+}
+
+// The Jalali conversions are based on the algorithm of the jalaali-js
+// project, which is accurate for the years -61 to 3177.
+
+var jalaliBreaks = []int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210, 1635, 2060, 2097,
+	2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+func jalaliCal(jy int) (leap, gy, march int) {
+	gy = jy + 621
+	leapJ := -14
+	jp := jalaliBreaks[0]
+	jump := 0
+
+	for _, jm := range jalaliBreaks[1:] {
+		jump = jm - jp
+		if jy < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+
+	n := jy - jp
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+
+	return leap, gy, march
+}
+
+func jalaliLeap(jy int) bool {
+	leap, _, _ := jalaliCal(jy)
+	return leap == 0
+}
+
+func jalaliToJDN(jy, jm, jd int) int {
+	_, gy, march := jalaliCal(jy)
+	return gregorianToJDN(gy, 3, march) + (jm-1)*31 - jm/7*(jm-7) + jd - 1
+}
+
+func jdnToJalali(jdn int) (int, int, int) {
+	gy, _, _ := jdnToGregorian(jdn)
+	jy := gy - 621
+	leap, _, march := jalaliCal(jy)
+	k := jdn - gregorianToJDN(gy, 3, march)
+
+	if k >= 0 {
+		if k <= 185 {
+			return jy, 1 + k/31, k%31 + 1
+		}
+		k -= 186
+	} else {
+		jy--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+
+	return jy, 7 + k/30, k%30 + 1
+}
+
+func gregorianToJDN(gy, gm, gd int) int {
+	d := (gy+(gm-8)/6+100100)*1461/4 + (153*((gm+9)%12)+2)/5 + gd - 34840408
+	return d - (gy+100100+(gm-8)/6)/100*3/4 + 752
+}
+
+func jdnToGregorian(jdn int) (int, int, int) {
+	j := 4*jdn + 139361631
+	j += (4*jdn+183187720)/146097*3/4*4 - 3908
+	i := j%1461/4*5 + 308
+	gd := i%153/5 + 1
+	gm := i/153%12 + 1
+	gy := j/1461 - 100100 + (8-gm)/6
+	return gy, gm, gd
+}
+
+const hijriEpoch = 1948440
+
+func hijriToJDN(y, m, d int) int {
+	return d + (59*(m-1)+1)/2 + (y-1)*354 + (3+11*y)/30 + hijriEpoch - 1
+}
+
+func jdnToHijri(jdn int) (int, int, int) {
+	y := (30*(jdn-hijriEpoch) + 10646) / 10631
+	m := 1
+	for m < 12 && jdn >= hijriToJDN(y, m+1, 1) {
+		m++
+	}
+	return y, m, jdn - hijriToJDN(y, m, 1) + 1
+}
+
+var monthNames = map[string]struct {
+	calendar Calendar
+	month    int
+}{}
+
+func init() {
+	names := []struct {
+		calendar Calendar
+		names    [12][]string
+	}{
+		{Jalali, [12][]string{
+			{"فروردین"}, {"اردیبهشت"}, {"خرداد"}, {"تیر"}, {"مرداد", "امرداد"}, {"شهریور"},
+			{"مهر"}, {"آبان"}, {"آذر"}, {"دی"}, {"بهمن"}, {"اسفند"},
+		}},
+		{Gregorian, [12][]string{
+			{"january", "jan", "ژانویه"},
+			{"february", "feb", "فوریه"},
+			{"march", "mar", "مارس"},
+			{"april", "apr", "آوریل"},
+			{"may", "مه", "می"},
+			{"june", "jun", "ژوئن"},
+			{"july", "jul", "ژوئیه", "جولای"},
+			{"august", "aug", "اوت", "آگوست"},
+			{"september", "sep", "sept", "سپتامبر"},
+			{"october", "oct", "اکتبر"},
+			{"november", "nov", "نوامبر"},
+			{"december", "dec", "دسامبر"},
+		}},
+		{Hijri, [12][]string{
+			{"محرم"},
+			{"صفر"},
+			{"ربیع الاول"},
+			{"ربیع الثانی", "ربیع الآخر"},
+			{"جمادی الاول", "جمادی الاولی"},
+			{"جمادی الثانی", "جمادی الثانیه", "جمادی الآخر"},
+			{"رجب"},
+			{"شعبان"},
+			{"رمضان"},
+			{"شوال"},
+			{"ذیقعده", "ذی القعده"},
+			{"ذیحجه", "ذی الحجه"},
+		}},
+	}
+
+	for _, c := range names {
+		for i, list := range c.names {
+			for _, name := range list {
+				monthNames[monthKey(name)] = struct {
+					calendar Calendar
+					month    int
+				}{c.calendar, i + 1}
+			}
+		}
+	}
+}
+
+func monthKey(name string) string {
+	return normalizeWord(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\u200c' {
+			return -1
+		}
+		return r
+	}, name))
+}
+
+const (
+	datePart      = `(\p{Nd}{1,2})`
+	dateYear      = `(\p{Nd}{4})`
+	dateSeparator = `[/\-.]`
+	dateMonthName = `([\p{L}\x{200C}]+(?:[\s\x{200C}]+ال[\p{L}\x{200C}]+)?)`
+)
+
+var dateRecognizers = []struct {
+	pattern *regexp.Regexp
+	parse   func(submatches []string) (Date, bool)
+}{
+	{
+		regexp.MustCompile(dateYear + dateSeparator + datePart + dateSeparator + datePart),
+		func(s []string) (Date, bool) {
+			return numericDate(atoi(s[1]), atoi(s[2]), atoi(s[3]))
+		},
+	},
+	{
+		regexp.MustCompile(datePart + dateSeparator + datePart + dateSeparator + dateYear),
+		func(s []string) (Date, bool) {
+			year, month, day := atoi(s[3]), atoi(s[2]), atoi(s[1])
+			if year >= 1700 && month > 12 && day <= 12 {
+				month, day = day, month
+			}
+			return numericDate(year, month, day)
+		},
+	},
+	{
+		regexp.MustCompile(datePart + `(?:ام|م|st|nd|rd|th)?\s+(?:of\s+)?` + dateMonthName + `(?:\s+ماه)?\s*[,،]?\s+` + dateYear),
+		func(s []string) (Date, bool) {
+			return namedDate(atoi(s[3]), s[2], atoi(s[1]))
+		},
+	},
+	{
+		regexp.MustCompile(dateMonthName + `\.?\s+` + datePart + `(?:st|nd|rd|th)?\s*,?\s+` + dateYear),
+		func(s []string) (Date, bool) {
+			return namedDate(atoi(s[3]), s[1], atoi(s[2]))
+		},
+	},
+}
+
+func atoi(digits string) int {
+	n, _ := strconv.Atoi(normalizeWord(digits))
+	return n
+}
+
+func numericDate(year, month, day int) (Date, bool) {
+	d := Date{Calendar: Jalali, Year: year, Month: month, Day: day}
+	if year >= 1700 {
+		d.Calendar = Gregorian
+	} else if year < 1200 {
+		return Date{}, false
+	}
+	return d, d.Valid()
+}
+
+func namedDate(year int, month string, day int) (Date, bool) {
+	name, ok := monthNames[monthKey(month)]
+	if !ok {
+		return Date{}, false
+	}
+	d := Date{Calendar: name.calendar, Year: year, Month: name.month, Day: day}
+	return d, d.Valid()
+}
+
+// ParseDate parses a Jalali, Gregorian or Hijri date, such as "۱۴۰۲/۰۵/۱۰",
+// "۱۰ مرداد ۱۴۰۲" or "August 1, 2023". Numeric dates with a year before 1700
+// are considered Jalali.
+func ParseDate(s string) (Date, bool) {
+	s = strings.TrimSpace(s)
+	for _, dr := range dateRecognizers {
+		submatches := dr.pattern.FindStringSubmatch(s)
+		if submatches == nil || len(submatches[0]) != len(s) {
+			continue
+		}
+		if d, ok := dr.parse(submatches); ok {
+			return d, true
+		}
+	}
+	return Date{}, false
+}
+
+func newDateRecognizers(c Calendar) []recognizer {
+	recognizers := make([]recognizer, len(dateRecognizers))
+	for i, dr := range dateRecognizers {
+		parse := dr.parse
+		recognizers[i] = recognizer{
+			tokenType: DateToken,
+			pattern:   dr.pattern,
+			canonicalize: func(submatches []string) (string, bool) {
+				d, ok := parse(submatches)
+				if !ok {
+					return "", false
+				}
+				return d.In(c).String(), true
+			},
+		}
+	}
+	return recognizers
+}
@@ -0,0 +1,73 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		text string
+		want ptpp.Date
+		ok   bool
+	}{
+		{"۱۴۰۲/۰۵/۱۰", ptpp.Date{Calendar: ptpp.Jalali, Year: 1402, Month: 5, Day: 10}, true},
+		{"10-05-1402", ptpp.Date{Calendar: ptpp.Jalali, Year: 1402, Month: 5, Day: 10}, true},
+		{"۱۰ مرداد ۱۴۰۲", ptpp.Date{Calendar: ptpp.Jalali, Year: 1402, Month: 5, Day: 10}, true},
+		{"۱۰ مرداد ماه ۱۴۰۲", ptpp.Date{Calendar: ptpp.Jalali, Year: 1402, Month: 5, Day: 10}, true},
+		{"2023-08-01", ptpp.Date{Calendar: ptpp.Gregorian, Year: 2023, Month: 8, Day: 1}, true},
+		{"August 1, 2023", ptpp.Date{Calendar: ptpp.Gregorian, Year: 2023, Month: 8, Day: 1}, true},
+		{"1st of August 2023", ptpp.Date{Calendar: ptpp.Gregorian, Year: 2023, Month: 8, Day: 1}, true},
+		{"۱۴ محرم ۱۴۴۵", ptpp.Date{Calendar: ptpp.Hijri, Year: 1445, Month: 1, Day: 14}, true},
+		{"۱۲ ربیع‌الاول ۱۴۴۵", ptpp.Date{Calendar: ptpp.Hijri, Year: 1445, Month: 3, Day: 12}, true},
+		{"1402/12/30", ptpp.Date{}, false},
+		{"1403/12/30", ptpp.Date{Calendar: ptpp.Jalali, Year: 1403, Month: 12, Day: 30}, true},
+		{"10 guitars 2023", ptpp.Date{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := ptpp.ParseDate(tt.text)
+			Equal(t, tt.ok, ok)
+			Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDateIn(t *testing.T) {
+	tests := []struct {
+		jalali    ptpp.Date
+		gregorian ptpp.Date
+	}{
+		{ptpp.Date{Calendar: ptpp.Jalali, Year: 1402, Month: 5, Day: 10}, ptpp.Date{Calendar: ptpp.Gregorian, Year: 2023, Month: 8, Day: 1}},
+		{ptpp.Date{Calendar: ptpp.Jalali, Year: 1403, Month: 1, Day: 1}, ptpp.Date{Calendar: ptpp.Gregorian, Year: 2024, Month: 3, Day: 20}},
+		{ptpp.Date{Calendar: ptpp.Jalali, Year: 1399, Month: 12, Day: 30}, ptpp.Date{Calendar: ptpp.Gregorian, Year: 2021, Month: 3, Day: 20}},
+		{ptpp.Date{Calendar: ptpp.Jalali, Year: 1357, Month: 11, Day: 22}, ptpp.Date{Calendar: ptpp.Gregorian, Year: 1979, Month: 2, Day: 11}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jalali.String(), func(t *testing.T) {
+			Equal(t, tt.gregorian, tt.jalali.In(ptpp.Gregorian))
+			Equal(t, tt.jalali, tt.gregorian.In(ptpp.Jalali))
+		})
+	}
+
+	hijri := ptpp.Date{Calendar: ptpp.Hijri, Year: 1445, Month: 1, Day: 1}
+	Equal(t, hijri, hijri.In(ptpp.Gregorian).In(ptpp.Hijri))
+}
+
+func TestProcessorRecognizeDates(t *testing.T) {
+	processor := ptpp.Processor{RecognizeDates: true}
+	processor.Train([]string{"bass guitar"})
+
+	got, err := processor.Process(strings.NewReader("bass guitarr ۱۰ مرداد ۱۴۰۲ concert 2023-08-01"))
+	NoError(t, err)
+	Equal(t, []string{"bass guitar", "1402-05-10", "concert", "1402-05-10"}, got)
+
+	processor.DateCalendar = ptpp.Gregorian
+	got, err = processor.Process(strings.NewReader("۱۴۰۲/۰۵/۱۰"))
+	NoError(t, err)
+	Equal(t, []string{"2023-08-01"}, got)
+}
@@ -0,0 +1,85 @@
+package ptpp
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DidYouMean corrects a raw query for a "Did you mean ...?" suggestion. The
+// misspelled words of the query are replaced by their corrections, and the
+// rest of the query, including its casing, spacing and punctuation, is kept
+// as is. It returns false if no word is corrected, i.e. if there is nothing
+// to suggest.
+func (p *Processor) DidYouMean(query string) (string, bool) {
+	suggestion, corrected, err := p.DidYouMeanContext(context.Background(), query)
+	if err != nil {
+		return query, false
+	}
+	return suggestion, corrected
+}
+
+// DidYouMeanContext is like DidYouMean, but it stops when ctx is done and
+// returns ctx.Err().
+func (p *Processor) DidYouMeanContext(ctx context.Context, query string) (string, bool, error) {
+	m := p.ensureFields()
+	b := phraseBuilder{p: p, m: m}
+	discard := func(Phrase) {}
+
+	sb := strings.Builder{}
+	offset := 0
+	corrected := false
+
+	spans := p.tokenSpans(m, query)
+	for i := 0; i < len(spans); {
+		if err := ctx.Err(); err != nil {
+			return "", false, err
+		}
+
+		s := spans[i]
+		if s.token.Type != WordToken {
+			b.flush(discard)
+			i++
+			continue
+		}
+
+		// The words of the target of a rule share the span of its source.
+		words := []string{}
+		changed := false
+		for ; i < len(spans) && spans[i].start == s.start && spans[i].token.Type == WordToken; i++ {
+			tok, err := b.addWord(ctx, spans[i].token, discard)
+			if err != nil {
+				return "", false, err
+			}
+			words = append(words, tok.Text)
+			changed = changed || tok.Status == Corrected
+		}
+		if !changed {
+			continue
+		}
+
+		sb.WriteString(query[offset:s.start])
+		sb.WriteString(matchCase(query[s.start:s.end], strings.Join(words, " ")))
+		offset = s.end
+		corrected = true
+	}
+
+	sb.WriteString(query[offset:])
+
+	return sb.String(), corrected, nil
+}
+
+// matchCase changes the case of a correction to match the original text, if
+// the original text is all upper-case or capitalized.
+func matchCase(original, correction string) string {
+	first, _ := utf8.DecodeRuneInString(original)
+	switch {
+	case strings.ToUpper(original) == original && strings.ToLower(original) != original:
+		return strings.ToUpper(correction)
+	case unicode.IsUpper(first):
+		r, size := utf8.DecodeRuneInString(correction)
+		return string(unicode.ToUpper(r)) + correction[size:]
+	}
+	return correction
+}
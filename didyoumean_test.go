@@ -0,0 +1,41 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorDidYouMean(t *testing.T) {
+	processor := ptpp.Processor{Rules: &ptpp.RuleSet{}}
+	processor.Train([]string{
+		"bass guitar",
+		"spanish rosetta stone",
+	})
+	processor.Rules.Add("pepsi cola", "pepsi")
+
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{"Electric BASE guitarr!", "Electric BASS guitar!", true},
+		{"  bass   guitar ", "  bass   guitar ", false},
+		{"Spannish  rosetta stone", "Spanish  rosetta stone", true},
+		{"Pepsi Cola, please", "Pepsi, please", true},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := processor.DidYouMean(tt.query)
+			Equal(t, tt.want, got)
+			Equal(t, tt.ok, ok)
+		})
+	}
+
+	got, _ := processor.Process(strings.NewReader("Electric BASE guitarr!"))
+	Equal(t, []string{"electric", "bass guitar"}, got)
+}
@@ -0,0 +1,92 @@
+package ptpp
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	entityDigit     = `\p{Nd}`
+	entitySeparator = `[\s\-]?`
+	entityZero      = `[0\x{06F0}\x{0660}]`
+	entityNine      = `[9\x{06F9}\x{0669}]`
+	entityIran      = `(?:\+|` + entityZero + `{2})` + entityNine + `[8\x{06F8}\x{0668}]`
+)
+
+var entityRecognizers = []recognizer{
+	{
+		tokenType: EmailToken,
+		pattern:   regexp.MustCompile(`[\p{L}\p{Nd}._%+\-]+@[\p{L}\p{Nd}\-]+(?:\.[\p{L}\p{Nd}\-]+)*\.\p{L}{2,}`),
+		canonicalize: func(s []string) (string, bool) {
+			return strings.ToLower(s[0]), true
+		},
+	},
+	{
+		tokenType: URLToken,
+		pattern:   regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"'«»]+`),
+		canonicalize: func(s []string) (string, bool) {
+			return canonicalURL(s[0])
+		},
+	},
+	{
+		tokenType: PhoneToken,
+		pattern: regexp.MustCompile(
+			// Iranian mobile numbers, e.g. 0912-345-6789 or +98 912 345 6789.
+			`(?:` + entityIran + `|` + entityZero + `)` + entitySeparator + entityNine + entityDigit + `{2}` + entitySeparator +
+				entityDigit + `{3}` + entitySeparator + entityDigit + `{4}` +
+				// Iranian landline numbers, e.g. 021-88776655.
+				`|` + entityZero + entityDigit + `{2}` + entitySeparator + entityDigit + `{8}` +
+				// International numbers, e.g. +1 555 123 4567.
+				`|\+` + entityDigit + `{1,3}` + entitySeparator + `(?:` + entityDigit + entitySeparator + `){6,12}` + entityDigit,
+		),
+		canonicalize: func(s []string) (string, bool) {
+			return canonicalPhone(s[0]), true
+		},
+	},
+	{
+		tokenType: HashtagToken,
+		pattern:   regexp.MustCompile(`#[\p{L}\p{Nd}_\x{200C}]+`),
+		canonicalize: func(s []string) (string, bool) {
+			return normalizeWord(s[0]), true
+		},
+	},
+}
+
+func canonicalURL(url string) (string, bool) {
+	url = strings.TrimRight(url, ".,;:!?)]}،؛؟")
+
+	hostStart := 0
+	if i := strings.Index(url, "://"); i >= 0 {
+		hostStart = i + 3
+	}
+	hostEnd := len(url)
+	if i := strings.IndexAny(url[hostStart:], "/?#"); i >= 0 {
+		hostEnd = hostStart + i
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(url[hostStart:hostEnd]), "www.")
+	if host == "" {
+		return "", false
+	}
+
+	return strings.ToLower(url[:hostEnd]) + url[hostEnd:], true
+}
+
+func canonicalPhone(phone string) string {
+	digits := normalizeWord(strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == '+' {
+			return r
+		}
+		return -1
+	}, phone))
+
+	switch {
+	case strings.HasPrefix(digits, "+98"):
+		return "0" + digits[3:]
+	case strings.HasPrefix(digits, "0098"):
+		return "0" + digits[4:]
+	}
+
+	return digits
+}
@@ -0,0 +1,70 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorRecognizeEntities(t *testing.T) {
+	processor := ptpp.Processor{RecognizeEntities: true}
+	processor.Train([]string{"info", "example", "bass guitar"})
+
+	tests := []struct {
+		text string
+		want []ptpp.Phrase
+	}{
+		{
+			"mail Info@Example.com now",
+			[]ptpp.Phrase{
+				{Tokens: []ptpp.Token{{Text: "mail", Type: ptpp.WordToken}}},
+				{Tokens: []ptpp.Token{{Text: "info@example.com", Type: ptpp.EmailToken}}},
+				{Tokens: []ptpp.Token{{Text: "now", Type: ptpp.WordToken}}},
+			},
+		},
+		{
+			"see HTTPS://Example.com/Path?q=1.",
+			[]ptpp.Phrase{
+				{Tokens: []ptpp.Token{{Text: "see", Type: ptpp.WordToken}}},
+				{Tokens: []ptpp.Token{{Text: "https://example.com/Path?q=1", Type: ptpp.URLToken}}},
+			},
+		},
+		{
+			"تماس ۰۹۱۲-۳۴۵-۶۷۸۹",
+			[]ptpp.Phrase{
+				{Tokens: []ptpp.Token{{Text: "تماس", Type: ptpp.WordToken}}},
+				{Tokens: []ptpp.Token{{Text: "09123456789", Type: ptpp.PhoneToken}}},
+			},
+		},
+		{
+			"+98 912 345 6789",
+			[]ptpp.Phrase{
+				{Tokens: []ptpp.Token{{Text: "09123456789", Type: ptpp.PhoneToken}}},
+			},
+		},
+		{
+			"#تهران bass guitarr",
+			[]ptpp.Phrase{
+				{Tokens: []ptpp.Token{{Text: "#تهران", Type: ptpp.HashtagToken}}},
+				{Tokens: []ptpp.Token{{Text: "bass", Type: ptpp.WordToken}, {Text: "guitar", Type: ptpp.WordToken}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			phrases, err := processor.ProcessPhrases(strings.NewReader(tt.text))
+			NoError(t, err)
+
+			got := make([]ptpp.Phrase, len(phrases))
+			for i, phrase := range phrases {
+				for _, tok := range phrase.Tokens {
+					got[i].Tokens = append(got[i].Tokens, ptpp.Token{Text: tok.Text, Type: tok.Type})
+				}
+			}
+			Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package ptpp
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Expander finds alternatives of a phrase for query expansion.
+type Expander interface {
+
+	// Expand finds the alternatives of a normalized phrase, excluding the
+	// phrase itself, ordered by their relevance.
+	Expand(phrase string) []string
+}
+
+// MultiExpander returns an Expander which combines the alternatives of the
+// given expanders, in order and without duplicates.
+func MultiExpander(expanders ...Expander) Expander {
+	return multiExpander(expanders)
+}
+
+type multiExpander []Expander
+
+func (me multiExpander) Expand(phrase string) []string {
+	alternatives := []string{}
+	seen := map[string]bool{phrase: true}
+	for _, e := range me {
+		for _, alternative := range e.Expand(phrase) {
+			if !seen[alternative] {
+				seen[alternative] = true
+				alternatives = append(alternatives, alternative)
+			}
+		}
+	}
+	return alternatives
+}
+
+// Thesaurus is an Expander which uses groups of synonymous phrases, such as
+// "laptop" and "notebook". The synonyms of a phrase are the other phrases of
+// its groups, followed by the phrase with each of its words replaced by the
+// synonyms of the word.
+type Thesaurus struct {
+	groups [][]string
+	index  map[string][]int
+	mutex  sync.RWMutex
+}
+
+// Add adds a group of synonymous phrases to the thesaurus. The phrases are
+// normalized like the input.
+func (th *Thesaurus) Add(synonyms ...string) {
+	th.mutex.Lock()
+	defer th.mutex.Unlock()
+
+	th.add(synonyms)
+}
+
+func (th *Thesaurus) add(synonyms []string) {
+	if th.index == nil {
+		th.index = make(map[string][]int)
+	}
+
+	group := []string{}
+	for _, synonym := range synonyms {
+		words, _ := readWords(strings.NewReader(synonym))
+		if len(words) > 0 {
+			group = append(group, strings.Join(words, " "))
+		}
+	}
+	if len(group) < 2 {
+		return
+	}
+
+	for _, phrase := range group {
+		th.index[phrase] = append(th.index[phrase], len(th.groups))
+	}
+	th.groups = append(th.groups, group)
+}
+
+// Len returns the number of synonym groups in the thesaurus.
+func (th *Thesaurus) Len() int {
+	th.mutex.RLock()
+	defer th.mutex.RUnlock()
+
+	return len(th.groups)
+}
+
+// Expand finds the synonyms of a normalized phrase.
+func (th *Thesaurus) Expand(phrase string) []string {
+	th.mutex.RLock()
+	defer th.mutex.RUnlock()
+
+	synonyms := []string{}
+	seen := map[string]bool{phrase: true}
+	add := func(synonym string) {
+		if !seen[synonym] {
+			seen[synonym] = true
+			synonyms = append(synonyms, synonym)
+		}
+	}
+
+	for _, synonym := range th.synonyms(phrase) {
+		add(synonym)
+	}
+
+	words := strings.Split(phrase, " ")
+	if len(words) < 2 {
+		return synonyms
+	}
+
+	for i, word := range words {
+		for _, synonym := range th.synonyms(word) {
+			replaced := append(append(append([]string{}, words[:i]...), synonym), words[i+1:]...)
+			add(strings.Join(replaced, " "))
+		}
+	}
+
+	return synonyms
+}
+
+func (th *Thesaurus) synonyms(phrase string) []string {
+	synonyms := []string{}
+	for _, i := range th.index[phrase] {
+		for _, synonym := range th.groups[i] {
+			if synonym != phrase {
+				synonyms = append(synonyms, synonym)
+			}
+		}
+	}
+	return synonyms
+}
+
+// Load adds the synonym groups read from r to the thesaurus. The input is a
+// plain text with one group of comma-separated phrases per line, e.g.
+// "گوشی، موبایل". Empty lines and lines starting with '#' are ignored.
+func (th *Thesaurus) Load(r io.Reader) error {
+	th.mutex.Lock()
+	defer th.mutex.Unlock()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		th.add(strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == '،'
+		}))
+	}
+
+	return scanner.Err()
+}
+
+// LoadFile adds the synonym groups of a plain text file to the thesaurus.
+func (th *Thesaurus) LoadFile(filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return th.Load(f)
+}
+
+// CooccurrenceExpander is an Expander which finds the words used in the same
+// contexts as a word, e.g. "گوشی" and "موبایل" in "گوشی سامسونگ" and
+// "موبایل سامسونگ", using the model of a DefaultSemanticMatcher. The
+// similarity of two words is the Jaccard index of their neighbouring words.
+type CooccurrenceExpander struct {
+
+	// MinSimilarity is the minimum similarity of an alternative, in the range
+	// of 0 to 1.
+	MinSimilarity float64
+
+	// MaxAlternatives is the maximum number of alternatives of a phrase. If
+	// this field is not positive, all alternatives are returned.
+	MaxAlternatives int
+
+	neighbours map[string][]string
+	words      map[string][]string
+}
+
+// NewCooccurrenceExpander creates a CooccurrenceExpander from a snapshot of
+// the model of a semantic matcher, with the given minimum similarity.
+func NewCooccurrenceExpander(sm *DefaultSemanticMatcher, minSimilarity float64) *CooccurrenceExpander {
+	neighbours := map[string][]string{}
+	words := map[string][]string{}
+	link := func(word, neighbour string) {
+		neighbours[word] = append(neighbours[word], neighbour)
+		words[neighbour] = append(words[neighbour], word)
+	}
+
+	sm.mutex.RLock()
+	for context, list := range sm.contexts {
+		for word := range list {
+			link(word, "<"+context)
+			link(context, ">"+word)
+		}
+	}
+	sm.mutex.RUnlock()
+
+	return &CooccurrenceExpander{
+		MinSimilarity: minSimilarity,
+		neighbours:    neighbours,
+		words:         words,
+	}
+}
+
+// Expand finds the words similar to a single-word phrase. Phrases of more
+// than one word have no alternatives.
+func (ce *CooccurrenceExpander) Expand(phrase string) []string {
+	neighbours, ok := ce.neighbours[phrase]
+	if !ok || strings.Contains(phrase, " ") {
+		return []string{}
+	}
+
+	shared := map[string]int{}
+	for _, neighbour := range neighbours {
+		for _, word := range ce.words[neighbour] {
+			if word != phrase {
+				shared[word]++
+			}
+		}
+	}
+
+	similarities := map[string]float64{}
+	alternatives := []string{}
+	for word, count := range shared {
+		similarity := float64(count) / float64(len(neighbours)+len(ce.neighbours[word])-count)
+		if similarity >= ce.MinSimilarity {
+			similarities[word] = similarity
+			alternatives = append(alternatives, word)
+		}
+	}
+
+	sort.Slice(alternatives, func(i, j int) bool {
+		si, sj := similarities[alternatives[i]], similarities[alternatives[j]]
+		if si != sj {
+			return si > sj
+		}
+		return alternatives[i] < alternatives[j]
+	})
+
+	if ce.MaxAlternatives > 0 && len(alternatives) > ce.MaxAlternatives {
+		alternatives = alternatives[:ce.MaxAlternatives]
+	}
+
+	return alternatives
+}
@@ -0,0 +1,65 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestThesaurus(t *testing.T) {
+	var thesaurus ptpp.Thesaurus
+	NoError(t, thesaurus.Load(strings.NewReader("# devices\nLaptop, Notebook\nگوشی، موبایل، تلفن همراه\nsingle\n")))
+	Equal(t, 2, thesaurus.Len())
+
+	tests := []struct {
+		phrase string
+		want   []string
+	}{
+		{"laptop", []string{"notebook"}},
+		{"تلفن همراه", []string{"گوشی", "موبایل"}},
+		{"laptop bag", []string{"notebook bag"}},
+		{"guitar", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			Equal(t, tt.want, thesaurus.Expand(tt.phrase))
+		})
+	}
+}
+
+func TestCooccurrenceExpander(t *testing.T) {
+	var sm ptpp.DefaultSemanticMatcher
+	for _, pair := range [][2]string{
+		{"گوشی", "سامسونگ"}, {"موبایل", "سامسونگ"},
+		{"گوشی", "اپل"}, {"موبایل", "اپل"},
+		{"گوشی", "نوکیا"}, {"کیف", "اپل"},
+	} {
+		sm.Train(pair[0], pair[1])
+	}
+
+	expander := ptpp.NewCooccurrenceExpander(&sm, 0.5)
+	Equal(t, []string{"موبایل"}, expander.Expand("گوشی"))
+	Equal(t, []string{}, expander.Expand("گوشی سامسونگ"))
+
+	expander.MinSimilarity = 0
+	expander.MaxAlternatives = 1
+	Equal(t, []string{"موبایل"}, expander.Expand("گوشی"))
+}
+
+func TestProcessorExpander(t *testing.T) {
+	var thesaurus ptpp.Thesaurus
+	thesaurus.Add("laptop", "notebook")
+	thesaurus.Add("bag", "case")
+
+	processor := ptpp.Processor{Expander: ptpp.MultiExpander(&thesaurus, &thesaurus)}
+	processor.Train([]string{"laptop bag"})
+
+	phrases, err := processor.ProcessPhrases(strings.NewReader("laptopp bag"))
+	if NoError(t, err) && Len(t, phrases, 1) {
+		Equal(t, "laptop bag", phrases[0].String())
+		Equal(t, []string{"notebook bag", "laptop case"}, phrases[0].Synonyms)
+	}
+}
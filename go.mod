@@ -1,5 +1,5 @@
 module gopkg.in/ptpp.v1
 
-go 1.15
+go 1.16
 
 require github.com/stretchr/testify v1.6.1
@@ -0,0 +1,217 @@
+package ptpp
+
+import (
+	"sort"
+	"strings"
+)
+
+// Language is the language of a token.
+type Language int
+
+const (
+	// OtherLanguage is the language of the tokens other than words, such as
+	// dates and entities, and of the phrases with words of several languages.
+	OtherLanguage Language = iota
+
+	// PersianLanguage is the language of the words in the Persian script.
+	PersianLanguage
+
+	// ArabicLanguage is the language of the words with the letters "ة" and
+	// "ى", which are specific to the Arabic script.
+	ArabicLanguage
+
+	// EnglishLanguage is the language of the words in the Latin script.
+	EnglishLanguage
+
+	// NumberLanguage is the language of numbers, which can be joined with the
+	// words of any language.
+	NumberLanguage
+)
+
+var languageNames = []string{"other", "persian", "arabic", "english", "number"}
+
+func (l Language) String() string {
+	if int(l) < len(languageNames) {
+		return languageNames[l]
+	}
+	return "unknown"
+}
+
+const (
+	// arabicLetters are the letters which are used in Arabic but not in
+	// Persian. The Arabic "ي" and "ك" are not included, as they are commonly
+	// typed in Persian text using Arabic keyboards, and neither are tanwin and
+	// hamza, as in "حتماً" and "مسأله".
+	arabicLetters = "ةى"
+
+	// persianLetters are the letters which are used in Persian but not in
+	// Arabic.
+	persianLetters = "پچژگیک"
+)
+
+// wordLanguage identifies the language of a word, given its text before the
+// normalization.
+func wordLanguage(text string) Language {
+	for _, r := range text {
+		switch {
+		case isEnglishLetter(r):
+			return EnglishLanguage
+		case isDigit(r):
+			return NumberLanguage
+		case isArabicOrFarsiLetter(r):
+			if strings.ContainsAny(text, arabicLetters) && !strings.ContainsAny(text, persianLetters) {
+				return ArabicLanguage
+			}
+			return PersianLanguage
+		}
+	}
+	return OtherLanguage
+}
+
+// Language returns the language of the phrase. Numbers take the language of
+// the other words of the phrase, and a phrase of several languages is of
+// OtherLanguage.
+func (ph Phrase) Language() Language {
+	if len(ph.Tokens) == 0 {
+		return OtherLanguage
+	}
+
+	language := NumberLanguage
+	for _, tok := range ph.Tokens {
+		switch {
+		case tok.Language == NumberLanguage:
+		case language == NumberLanguage:
+			language = tok.Language
+		case tok.Language != language:
+			return OtherLanguage
+		}
+	}
+	return language
+}
+
+// joinLanguage returns the language of a phrase after adding a word to it,
+// which is NumberLanguage as long as the phrase only has numbers. It returns
+// false if the word is of a different language.
+func joinLanguage(phrase, word Language) (Language, bool) {
+	switch {
+	case word == NumberLanguage || word == phrase:
+		return phrase, true
+	case phrase == NumberLanguage:
+		return word, true
+	}
+	return phrase, false
+}
+
+// LanguageModel holds the components which handle the words of a language.
+type LanguageModel struct {
+
+	// SpellChecker is the spell-checker of the words of the language. If this
+	// field is nil, the preprocessor will use DefaultSpellChecker.
+	SpellChecker SpellChecker
+
+	// SemanticMatcher is the semantic matcher of the words of the language. If
+	// this field is nil, the preprocessor will use DefaultSemanticMatcher.
+	SemanticMatcher SemanticMatcher
+}
+
+// defaultLanguage denotes the languages without specific models, whose words
+// are handled by the default components of the processor.
+const defaultLanguage Language = -1
+
+// parseLanguage finds the language of a name returned by Language.String.
+func parseLanguage(name string) (Language, bool) {
+	for i, languageName := range languageNames {
+		if languageName == name {
+			return Language(i), true
+		}
+	}
+	return OtherLanguage, false
+}
+
+// withDefaultModels returns a copy of the language models, with the default
+// components in place of the nil ones.
+func withDefaultModels(languages map[Language]LanguageModel) map[Language]LanguageModel {
+	copied := make(map[Language]LanguageModel, len(languages))
+	for language, lm := range languages {
+		if lm.SpellChecker == nil {
+			lm.SpellChecker = &DefaultSpellChecker{}
+		}
+		if lm.SemanticMatcher == nil {
+			lm.SemanticMatcher = &DefaultSemanticMatcher{}
+		}
+		copied[language] = lm
+	}
+	return copied
+}
+
+// addLanguages adds the models of the languages which have no specific models
+// yet. The languages map is replaced, as it may be used by the processes in
+// progress.
+func (p *Processor) addLanguages(languages map[Language]LanguageModel) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	merged := make(map[Language]LanguageModel, len(p.Languages)+len(languages))
+	for language, lm := range p.Languages {
+		merged[language] = lm
+	}
+	for language, lm := range languages {
+		if _, ok := merged[language]; !ok {
+			merged[language] = lm
+		}
+	}
+	p.Languages = withDefaultModels(merged)
+}
+
+// route returns the language whose models handle the words of a language.
+func (m models) route(language Language) Language {
+	if _, ok := m.languages[language]; ok {
+		return language
+	}
+	return defaultLanguage
+}
+
+// language returns the models which handle the words of a language.
+func (m models) language(language Language) models {
+	if lm, ok := m.languages[language]; ok {
+		m.spellChecker = lm.SpellChecker
+		m.semanticMatcher = lm.SemanticMatcher
+	}
+	return m
+}
+
+// sortedLanguages returns the languages with specific models, in order.
+func (m models) sortedLanguages() []Language {
+	languages := make([]Language, 0, len(m.languages))
+	for language := range m.languages {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i] < languages[j]
+	})
+	return languages
+}
+
+// all returns the models of all languages, the default ones first.
+func (m models) all() []models {
+	all := []models{m}
+	for _, language := range m.sortedLanguages() {
+		all = append(all, m.language(language))
+	}
+	return all
+}
+
+// groupWords groups the words of a run by the languages of their models, in
+// the order of their first occurrence.
+func (m models) groupWords(run []Token) ([]Language, map[Language][]string) {
+	languages := []Language{}
+	words := map[Language][]string{}
+	for _, tok := range run {
+		language := m.route(tok.Language)
+		if _, ok := words[language]; !ok {
+			languages = append(languages, language)
+		}
+		words[language] = append(words[language], tok.Text)
+	}
+	return languages, words
+}
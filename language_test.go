@@ -0,0 +1,164 @@
+package ptpp_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestTokenLanguage(t *testing.T) {
+	processor := &ptpp.Processor{RecognizeEntities: true}
+
+	tests := []struct {
+		text string
+		want ptpp.Language
+	}{
+		{"کتاب", ptpp.PersianLanguage},
+		{"كتاب", ptpp.PersianLanguage},
+		{"مدرسة", ptpp.ArabicLanguage},
+		{"مستشفى", ptpp.ArabicLanguage},
+		{"حتماً", ptpp.PersianLanguage},
+		{"فوراً", ptpp.PersianLanguage},
+		{"مسأله", ptpp.PersianLanguage},
+		{"Guitar", ptpp.EnglishLanguage},
+		{"۱۴۰۲", ptpp.NumberLanguage},
+		{"#ptpp", ptpp.OtherLanguage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			phrases, err := processor.ProcessPhrases(strings.NewReader(tt.text))
+			if NoError(t, err) && Len(t, phrases, 1) && Len(t, phrases[0].Tokens, 1) {
+				Equal(t, tt.want, phrases[0].Tokens[0].Language)
+			}
+		})
+	}
+}
+
+func TestPhraseLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []ptpp.Language
+		want      ptpp.Language
+	}{
+		{"empty", nil, ptpp.OtherLanguage},
+		{"single", []ptpp.Language{ptpp.EnglishLanguage}, ptpp.EnglishLanguage},
+		{"numbers", []ptpp.Language{ptpp.NumberLanguage, ptpp.NumberLanguage}, ptpp.NumberLanguage},
+		{"with number", []ptpp.Language{ptpp.NumberLanguage, ptpp.PersianLanguage}, ptpp.PersianLanguage},
+		{"mixed", []ptpp.Language{ptpp.PersianLanguage, ptpp.EnglishLanguage}, ptpp.OtherLanguage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phrase := ptpp.Phrase{}
+			for _, language := range tt.languages {
+				phrase.Tokens = append(phrase.Tokens, ptpp.Token{Language: language})
+			}
+			Equal(t, tt.want, phrase.Language())
+		})
+	}
+}
+
+func TestProcessorSplitLanguages(t *testing.T) {
+	const text = "گوشی samsung ۱۲۸ گیگ"
+
+	mixed := &ptpp.Processor{}
+	mixed.Train([]string{text})
+
+	got, err := mixed.Process(strings.NewReader(text))
+	if NoError(t, err) {
+		Equal(t, []string{"گوشی samsung 128 گیگ"}, got)
+	}
+
+	mixed.SplitLanguages = true
+	got, err = mixed.Process(strings.NewReader(text))
+	if NoError(t, err) {
+		Equal(t, []string{"گوشی", "samsung 128", "گیگ"}, got)
+	}
+
+	hypotheses, err := mixed.ProcessNBest(strings.NewReader(text), 1)
+	if NoError(t, err) && Len(t, hypotheses, 1) {
+		for _, phrase := range hypotheses[0].Phrases {
+			NotEqual(t, ptpp.OtherLanguage, phrase.Language())
+		}
+	}
+
+	split := &ptpp.Processor{SplitLanguages: true}
+	split.Train([]string{text})
+	split.SplitLanguages = false
+
+	got, err = split.Process(strings.NewReader(text))
+	if NoError(t, err) {
+		Equal(t, []string{"گوشی", "samsung 128", "گیگ"}, got)
+	}
+}
+
+func TestProcessorLanguages(t *testing.T) {
+	processor := &ptpp.Processor{
+		Languages: map[ptpp.Language]ptpp.LanguageModel{ptpp.EnglishLanguage: {}},
+	}
+	processor.Train([]string{"گوشی samsung", "کیف samsung"})
+
+	english := processor.Languages[ptpp.EnglishLanguage].SpellChecker
+	Contains(t, english.Check("samsng"), "samsung")
+	NotContains(t, processor.SpellChecker.Check("samsng"), "samsung")
+	Contains(t, processor.SpellChecker.Check("گوش"), "گوشی")
+
+	got, err := processor.Process(strings.NewReader("گوشی samsng"))
+	if NoError(t, err) {
+		Equal(t, []string{"گوشی samsung"}, got)
+	}
+
+	var buf bytes.Buffer
+	if !NoError(t, processor.SaveTo(&buf)) {
+		return
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if NoError(t, err) {
+		names := []string{}
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		Subset(t, names, []string{"sc.gob", "sm.gob", "english/sc.gob", "english/sm.gob"})
+	}
+
+	var loaded ptpp.Processor
+	if NoError(t, loaded.LoadFrom(bytes.NewReader(buf.Bytes()), int64(buf.Len()))) {
+		Contains(t, loaded.Languages, ptpp.EnglishLanguage)
+		got, err := loaded.Process(strings.NewReader("کیف samsng"))
+		if NoError(t, err) {
+			Equal(t, []string{"کیف samsung"}, got)
+		}
+	}
+
+	arabic := &ptpp.Processor{
+		Languages: map[ptpp.Language]ptpp.LanguageModel{ptpp.ArabicLanguage: {}},
+	}
+	err = arabic.LoadFrom(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	var missing *ptpp.MissingEntryError
+	if True(t, errors.As(err, &missing)) {
+		Equal(t, "arabic/sc.gob", missing.Entry)
+	}
+	Len(t, arabic.Languages, 1)
+	Contains(t, arabic.Languages, ptpp.ArabicLanguage)
+
+	var plain bytes.Buffer
+	if NoError(t, (&ptpp.Processor{}).SaveTo(&plain)) {
+		err := loaded.LoadFrom(bytes.NewReader(plain.Bytes()), int64(plain.Len()))
+		True(t, errors.As(err, &missing))
+		if Contains(t, loaded.Languages, ptpp.EnglishLanguage) {
+			Contains(t, loaded.Languages[ptpp.EnglishLanguage].SpellChecker.Check("samsng"), "samsung")
+		}
+	}
+
+	var merged ptpp.Processor
+	if NoError(t, merged.Merge(processor)) {
+		Contains(t, merged.Languages, ptpp.EnglishLanguage)
+		Contains(t, merged.Languages[ptpp.EnglishLanguage].SpellChecker.Check("samsng"), "samsung")
+	}
+}
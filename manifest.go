@@ -0,0 +1,256 @@
+package ptpp
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+)
+
+// Version is the version of the library, which is stored in saved models.
+const Version = "1.1.0"
+
+// FormatVersion is the version of the model file format written by Save.
+// Models saved before the introduction of the manifest have format version 0.
+const FormatVersion = 1
+
+const manifestFileName = "manifest.json"
+
+// Manifest describes the content of a saved model.
+type Manifest struct {
+
+	// FormatVersion is the version of the model file format.
+	FormatVersion int `json:"format_version"`
+
+	// LibraryVersion is the version of the library which saved the model.
+	LibraryVersion string `json:"library_version"`
+
+	// Components are the saved components, keyed by their entry names.
+	Components map[string]ManifestComponent `json:"components"`
+
+	// Training holds the training metadata of the model.
+	Training TrainingInfo `json:"training"`
+}
+
+// ManifestComponent describes a saved component of a model.
+type ManifestComponent struct {
+
+	// Type is the Go type of the component, e.g. "*ptpp.DefaultSpellChecker".
+	Type string `json:"type"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the component entry.
+	SHA256 string `json:"sha256"`
+}
+
+// TrainingInfo holds the training metadata of a model.
+type TrainingInfo struct {
+
+	// Phrases is the number of phrases the model is trained with.
+	Phrases int64 `json:"phrases"`
+
+	// UpdatedAt is the time of the last training.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FormatVersionError is returned when loading a model with an unsupported
+// file format version.
+type FormatVersionError struct {
+	Version int
+}
+
+func (e *FormatVersionError) Error() string {
+	return fmt.Sprintf("ptpp: unsupported model format version %d (supported up to %d)", e.Version, FormatVersion)
+}
+
+// ComponentTypeError is returned when a model component was saved by a
+// different type than the one loading it.
+type ComponentTypeError struct {
+	Entry string
+	Saved string
+	Type  string
+}
+
+func (e *ComponentTypeError) Error() string {
+	return fmt.Sprintf("ptpp: model entry %q was saved by %s and cannot be loaded by %s", e.Entry, e.Saved, e.Type)
+}
+
+// ChecksumError is returned when a model entry does not match its checksum in
+// the manifest.
+type ChecksumError struct {
+	Entry string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("ptpp: model entry %q is corrupted (checksum mismatch)", e.Entry)
+}
+
+// MissingEntryError is returned when a required model entry does not exist.
+type MissingEntryError struct {
+	Entry string
+}
+
+func (e *MissingEntryError) Error() string {
+	return fmt.Sprintf("ptpp: model entry %q is missing", e.Entry)
+}
+
+// UnknownEntryError is returned when a model contains an entry which is not
+// described by its manifest.
+type UnknownEntryError struct {
+	Entry string
+}
+
+func (e *UnknownEntryError) Error() string {
+	return fmt.Sprintf("ptpp: model entry %q is unknown", e.Entry)
+}
+
+// ReadManifest reads the manifest of a saved model. The manifest of a model
+// saved before format version 1 is synthesized from its entries.
+func ReadManifest(filePath string) (*Manifest, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return readManifest(&zr.Reader)
+}
+
+func readManifest(zr *zip.Reader) (*Manifest, error) {
+	for _, f := range zr.File {
+		if f.Name != manifestFileName {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		var m Manifest
+		if err := json.NewDecoder(r).Decode(&m); err != nil {
+			return nil, err
+		}
+		if m.FormatVersion < 1 || m.FormatVersion > FormatVersion {
+			return nil, &FormatVersionError{Version: m.FormatVersion}
+		}
+
+		return &m, nil
+	}
+
+	m := &Manifest{Components: map[string]ManifestComponent{}}
+	for _, f := range zr.File {
+		m.Components[f.Name] = ManifestComponent{}
+	}
+
+	return m, nil
+}
+
+// languages returns the languages with specific models in the model, which
+// are stored in the entries prefixed by the names of the languages.
+func (m *Manifest) languages() []Language {
+	languages := []Language{}
+	seen := map[Language]bool{}
+	for name := range m.Components {
+		i := strings.Index(name, "/")
+		if i < 0 {
+			continue
+		}
+		if language, ok := parseLanguage(name[:i]); ok && !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// validate checks that the entries of zr match the manifest and can be loaded
+// into the components.
+func (m *Manifest) validate(zr *zip.Reader, components []component) error {
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		if f.Name == manifestFileName {
+			continue
+		}
+		if _, ok := m.Components[f.Name]; !ok {
+			return &UnknownEntryError{Entry: f.Name}
+		}
+		files[f.Name] = f
+	}
+
+	for name, mc := range m.Components {
+		f, ok := files[name]
+		if !ok {
+			return &MissingEntryError{Entry: name}
+		}
+		if m.FormatVersion == 0 {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		if hex.EncodeToString(h.Sum(nil)) != mc.SHA256 {
+			return &ChecksumError{Entry: name}
+		}
+	}
+
+	for _, c := range components {
+		mc, ok := m.Components[c.name]
+		if !ok {
+			if !c.optional && isLoadSaver(c.value) {
+				return &MissingEntryError{Entry: c.name}
+			}
+			continue
+		}
+		if m.FormatVersion == 0 {
+			continue
+		}
+		if typ := typeName(c.value); mc.Type != typ {
+			return &ComponentTypeError{Entry: c.name, Saved: mc.Type, Type: typ}
+		}
+	}
+
+	return nil
+}
+
+func typeName(v interface{}) string {
+	return fmt.Sprintf("%T", v)
+}
+
+func isLoadSaver(v interface{}) bool {
+	_, ok := v.(LoadSaver)
+	return ok
+}
+
+// checksumWriter computes the checksum of the data written through it.
+type checksumWriter struct {
+	w    io.Writer
+	hash hash.Hash
+}
+
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	return &checksumWriter{w: w, hash: sha256.New()}
+}
+
+func (cw *checksumWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.hash.Write(p[:n])
+	return n, err
+}
+
+func (cw *checksumWriter) Sum() string {
+	return hex.EncodeToString(cw.hash.Sum(nil))
+}
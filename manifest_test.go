@@ -0,0 +1,128 @@
+package ptpp_test
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type customSpellChecker struct{ ptpp.DefaultSpellChecker }
+
+func writeZip(t *testing.T, filePath string, entries map[string]string) {
+	f, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if !NoError(t, err) {
+		return
+	}
+
+	filePath := path.Join(workingDir, "test-manifest.zip")
+	defer os.Remove(filePath)
+
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar", "garbage collector"})
+	if !NoError(t, processor.Save(filePath)) {
+		return
+	}
+
+	manifest, err := ptpp.ReadManifest(filePath)
+	if NoError(t, err) {
+		Equal(t, ptpp.FormatVersion, manifest.FormatVersion)
+		Equal(t, ptpp.Version, manifest.LibraryVersion)
+		Equal(t, "*ptpp.DefaultSpellChecker", manifest.Components["sc.gob"].Type)
+		Len(t, manifest.Components["sc.gob"].SHA256, 64)
+		Equal(t, int64(2), manifest.Training.Phrases)
+	}
+
+	var loaded ptpp.Processor
+	if NoError(t, loaded.Load(filePath)) {
+		Equal(t, int64(2), loaded.Training().Phrases)
+	}
+
+	custom := ptpp.Processor{SpellChecker: &customSpellChecker{}}
+	var typeErr *ptpp.ComponentTypeError
+	if True(t, errors.As(custom.Load(filePath), &typeErr)) {
+		Equal(t, "sc.gob", typeErr.Entry)
+	}
+
+	tests := []struct {
+		name    string
+		entries map[string]string
+		target  interface{}
+	}{
+		{
+			"Legacy missing entry",
+			map[string]string{"sc.gob": ""},
+			new(*ptpp.MissingEntryError),
+		},
+		{
+			"Format version",
+			map[string]string{"manifest.json": `{"format_version": 99}`},
+			new(*ptpp.FormatVersionError),
+		},
+		{
+			"Unknown entry",
+			map[string]string{"manifest.json": `{"format_version": 1}`, "xx.gob": ""},
+			new(*ptpp.UnknownEntryError),
+		},
+		{
+			"Missing entry",
+			map[string]string{"manifest.json": `{"format_version": 1, "components": {"sc.gob": {"type": "*ptpp.DefaultSpellChecker"}}}`},
+			new(*ptpp.MissingEntryError),
+		},
+		{
+			"Empty manifest",
+			map[string]string{"manifest.json": `{"format_version": 1}`},
+			new(*ptpp.MissingEntryError),
+		},
+		{
+			"Checksum",
+			map[string]string{
+				"manifest.json": `{"format_version": 1, "components": {"sc.gob": {"type": "*ptpp.DefaultSpellChecker", "sha256": "00"}}}`,
+				"sc.gob":        "corrupted",
+			},
+			new(*ptpp.ChecksumError),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeZip(t, filePath, tt.entries)
+
+			var processor ptpp.Processor
+			True(t, errors.As(processor.Load(filePath), tt.target))
+		})
+	}
+
+	writeZip(t, filePath, map[string]string{
+		"manifest.json": `{"format_version": 1, "components": {"sm.gob": {"type": "*ptpp.DefaultSemanticMatcher", "sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}}}`,
+		"sm.gob":        "",
+	})
+	var missingErr *ptpp.MissingEntryError
+	if True(t, errors.As(loaded.Load(filePath), &missingErr)) {
+		Equal(t, "sc.gob", missingErr.Entry)
+	}
+}
@@ -0,0 +1,186 @@
+package ptpp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Merge adds the model of another processor to the processor, summing the
+// frequencies and unioning the vocabularies. Both processors must use the
+// same types of components, and the processor is not changed if they do not.
+// The components shared by the processors are not merged. The models of the
+// languages which only the other processor has are added to the processor.
+func (p *Processor) Merge(other *Processor) error {
+	if p == other {
+		return errors.New("ptpp: cannot merge a processor into itself")
+	}
+
+	src := other.ensureFields()
+	dst := p.ensureFields()
+
+	added := map[Language]LanguageModel{}
+	languages := map[Language]LanguageModel{}
+	for language, lm := range dst.languages {
+		languages[language] = lm
+	}
+	for language := range src.languages {
+		if _, ok := languages[language]; !ok {
+			added[language] = LanguageModel{SpellChecker: &DefaultSpellChecker{}, SemanticMatcher: &DefaultSemanticMatcher{}}
+			languages[language] = added[language]
+		}
+	}
+	dst.languages = languages
+
+	components := map[string]interface{}{}
+	for _, c := range dst.components() {
+		components[c.name] = c.value
+	}
+
+	srcComponents := src.components()
+	for _, c := range srcComponents {
+		if dst := components[c.name]; c.value != dst && !canMerge(dst, c.value) {
+			return fmt.Errorf("ptpp: cannot merge %T into %T", c.value, dst)
+		}
+	}
+
+	p.addLanguages(added)
+	for _, c := range srcComponents {
+		if c.value != components[c.name] {
+			mergeComponent(components[c.name], c.value)
+		}
+	}
+
+	training := other.Training()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.training.Phrases += training.Phrases
+	if training.UpdatedAt.After(p.training.UpdatedAt) {
+		p.training.UpdatedAt = training.UpdatedAt
+	}
+
+	return nil
+}
+
+// canMerge checks whether the src component can be merged into dst.
+func canMerge(dst, src interface{}) bool {
+	ok := false
+	switch dst.(type) {
+	case *DefaultSpellChecker:
+		_, ok = src.(*DefaultSpellChecker)
+	case *DefaultSemanticMatcher:
+		_, ok = src.(*DefaultSemanticMatcher)
+	case *WordSet:
+		_, ok = src.(*WordSet)
+	case *RuleSet:
+		_, ok = src.(*RuleSet)
+	}
+	return ok
+}
+
+// mergeComponent merges the src component into dst, which must be checked by
+// canMerge.
+func mergeComponent(dst, src interface{}) {
+	switch dst := dst.(type) {
+	case *DefaultSpellChecker:
+		dst.Merge(src.(*DefaultSpellChecker))
+	case *DefaultSemanticMatcher:
+		dst.Merge(src.(*DefaultSemanticMatcher))
+	case *WordSet:
+		dst.Merge(src.(*WordSet))
+	case *RuleSet:
+		dst.Merge(src.(*RuleSet))
+	}
+}
+
+// MergeModels merges the saved models of srcPaths into a new model saved at
+// dstPath. The models are loaded into empty components of the same types and
+// options as the ones of template, which may be nil for the default ones.
+func MergeModels(template *Processor, dstPath string, srcPaths ...string) error {
+	merged := newFromTemplate(template)
+
+	for _, srcPath := range srcPaths {
+		p := newFromTemplate(template)
+		if err := p.Load(srcPath); err != nil {
+			return err
+		}
+		if err := merged.Merge(p); err != nil {
+			return err
+		}
+	}
+
+	return merged.Save(dstPath)
+}
+
+// newFromTemplate creates an empty processor like template, or a default one
+// if template is nil.
+func newFromTemplate(template *Processor) *Processor {
+	if template == nil {
+		return &Processor{}
+	}
+	return template.newEmpty()
+}
+
+// TrainReader trains the preprocessing model with the phrases read from r, one
+// phrase per line.
+func (p *Processor) TrainReader(r io.Reader) error {
+	return readPhraseBatches(r, p.Train)
+}
+
+// readPhraseBatches reads the phrases of r, one phrase per line, and passes
+// them to fn in batches. Each batch is a new slice.
+func readPhraseBatches(r io.Reader, fn func(batch []string)) error {
+	const batchSize = 1024
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	batch := make([]string, 0, batchSize)
+	for scanner.Scan() {
+		phrase := strings.TrimSpace(scanner.Text())
+		if phrase == "" {
+			continue
+		}
+
+		batch = append(batch, phrase)
+		if len(batch) == batchSize {
+			fn(batch)
+			batch = make([]string, 0, batchSize)
+		}
+	}
+
+	if len(batch) > 0 {
+		fn(batch)
+	}
+
+	return scanner.Err()
+}
+
+// ApplyDelta trains the saved model of modelPath with the phrases of the delta
+// file of deltaPath, one phrase per line, and saves it in place. The model is
+// loaded into empty components of the same types and options as the ones of
+// template, which may be nil for the default ones, and it is trained with the
+// tokenization options of template.
+func ApplyDelta(template *Processor, modelPath, deltaPath string) error {
+	p := newFromTemplate(template)
+	if err := p.Load(modelPath); err != nil {
+		return err
+	}
+
+	f, err := os.Open(deltaPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := p.TrainReader(f); err != nil {
+		return err
+	}
+
+	return p.Save(modelPath)
+}
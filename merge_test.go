@@ -0,0 +1,102 @@
+package ptpp_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorMerge(t *testing.T) {
+	var first, second, all ptpp.Processor
+	first.Train([]string{"bass guitar", "quality"})
+	second.Train([]string{"bass drum", "quality", "quantity"})
+	all.Train([]string{"bass guitar", "quality", "bass drum", "quality", "quantity"})
+
+	if !NoError(t, first.Merge(&second)) {
+		return
+	}
+	Equal(t, int64(5), first.Training().Phrases)
+
+	for _, phrase := range []string{"base guitarr", "bas drumm", "quanlity"} {
+		want, _ := all.ProcessPhrases(strings.NewReader(phrase))
+		got, _ := first.ProcessPhrases(strings.NewReader(phrase))
+		Equal(t, want, got, phrase)
+	}
+
+	custom := ptpp.Processor{SpellChecker: &customSpellChecker{}}
+	Error(t, first.Merge(&custom))
+
+	partial := ptpp.Processor{SemanticMatcher: &customSemanticMatcher{}}
+	partial.Train([]string{"garbage collector"})
+	Error(t, first.Merge(&partial))
+	Equal(t, int64(5), first.Training().Phrases)
+	NotContains(t, first.SpellChecker.Check("garbag"), "garbage")
+
+	Error(t, first.Merge(&first))
+	Equal(t, int64(5), first.Training().Phrases)
+}
+
+type customSemanticMatcher struct{ ptpp.DefaultSemanticMatcher }
+
+func TestMergeModels(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if !NoError(t, err) {
+		return
+	}
+
+	firstPath := path.Join(workingDir, "test-first.zip")
+	secondPath := path.Join(workingDir, "test-second.zip")
+	mergedPath := path.Join(workingDir, "test-merged.zip")
+	deltaPath := path.Join(workingDir, "test-delta.txt")
+	for _, filePath := range []string{firstPath, secondPath, mergedPath, deltaPath} {
+		defer os.Remove(filePath)
+	}
+
+	var first, second ptpp.Processor
+	first.Train([]string{"bass guitar"})
+	second.Train([]string{"garbage collector"})
+	if !NoError(t, first.Save(firstPath)) || !NoError(t, second.Save(secondPath)) {
+		return
+	}
+
+	if !NoError(t, ptpp.MergeModels(nil, mergedPath, firstPath, secondPath)) {
+		return
+	}
+	if !NoError(t, ioutil.WriteFile(deltaPath, []byte("spanish rosetta stone\n\nelectric guitar\n"), 0644)) {
+		return
+	}
+	if !NoError(t, ptpp.ApplyDelta(nil, mergedPath, deltaPath)) {
+		return
+	}
+
+	var merged ptpp.Processor
+	if !NoError(t, merged.Load(mergedPath)) {
+		return
+	}
+	Equal(t, int64(4), merged.Training().Phrases)
+
+	got, err := merged.Process(strings.NewReader("base guitarr garbage colector spannish rosetta stone"))
+	NoError(t, err)
+	Equal(t, []string{"bass guitar", "garbage collector", "spanish rosetta stone"}, got)
+
+	template := &ptpp.Processor{ConvertNumbers: true}
+	if !NoError(t, ioutil.WriteFile(deltaPath, []byte("thirty five mm lens\n"), 0644)) {
+		return
+	}
+	if !NoError(t, ptpp.ApplyDelta(template, mergedPath, deltaPath)) {
+		return
+	}
+	if NoError(t, merged.Load(mergedPath)) {
+		Contains(t, merged.SpellChecker.Check("36"), "35")
+		NotContains(t, merged.SpellChecker.Check("thirt"), "thirty")
+	}
+
+	custom := &ptpp.Processor{SpellChecker: &customSpellChecker{}}
+	Error(t, ptpp.MergeModels(custom, mergedPath, firstPath))
+}
@@ -0,0 +1,174 @@
+package ptpp
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	// splitPenalty is the log-probability of not joining two words which
+	// could be joined into a phrase.
+	splitPenalty = -math.Ln2
+
+	// minHypothesisConfidence is the confidence used for scoring unknown
+	// words, whose confidence is zero.
+	minHypothesisConfidence = 1e-6
+
+	// beamFactor is the number of hypotheses kept at each step of the beam
+	// search, relative to the number of requested hypotheses.
+	beamFactor = 4
+)
+
+// Hypothesis is an interpretation of the processed text, i.e. a segmentation
+// of the text into phrases along with the corrections of its words.
+type Hypothesis struct {
+
+	// Phrases are the phrases of the hypothesis.
+	Phrases []Phrase
+
+	// Score is the estimated log-probability of the hypothesis. It is the sum
+	// of the logarithms of the confidences of the words, plus log(1/2) for
+	// each pair of consecutive words which could form a phrase but are split.
+	Score float64
+}
+
+// ProcessNBest does the preprocessing on an input and finds the n best
+// hypotheses, ordered by their scores. The hypothesis with the highest score
+// is not necessarily the result of Process, which does a greedy search.
+func (p *Processor) ProcessNBest(r io.Reader, n int) ([]Hypothesis, error) {
+	return p.ProcessNBestContext(context.Background(), r, n)
+}
+
+// ProcessNBestContext is like ProcessNBest, but it stops when ctx is done and
+// returns ctx.Err().
+func (p *Processor) ProcessNBestContext(ctx context.Context, r io.Reader, n int) ([]Hypothesis, error) {
+	if n <= 0 {
+		return []Hypothesis{}, nil
+	}
+
+	m := p.ensureFields()
+	tokens, err := p.readTokens(m, r)
+	if err != nil {
+		return nil, err
+	}
+
+	beam := []hypothesisState{{}}
+	for _, tok := range tokens {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if tok.Type != WordToken {
+			for i := range beam {
+				beam[i].last = &hypothesisNode{tok: tok, prev: beam[i].last}
+			}
+			continue
+		}
+
+		suggestions, err := p.suggest(ctx, m, tok)
+		if err != nil {
+			return nil, err
+		}
+
+		next := []hypothesisState{}
+		for _, state := range beam {
+			for _, s := range suggestions {
+				word := spelledToken(tok, s)
+				score := state.score + math.Log(math.Max(s.Confidence, minHypothesisConfidence))
+
+				if prev := state.last; prev != nil && prev.tok.Type == WordToken {
+					language, joined := prev.language, true
+					if p.SplitLanguages {
+						language, joined = joinLanguage(language, word.Language)
+					}
+
+					matched := false
+					if joined {
+						_, matched, err = m.language(word.Language).match(ctx, prev.tok.Text, []string{s.Word})
+						if err != nil {
+							return nil, err
+						}
+					}
+					if matched {
+						next = append(next, hypothesisState{
+							last:  &hypothesisNode{tok: word, language: language, joined: true, prev: prev},
+							score: score,
+						})
+						score += splitPenalty
+					}
+				}
+
+				next = append(next, hypothesisState{
+					last:  &hypothesisNode{tok: word, language: word.Language, prev: state.last},
+					score: score,
+				})
+			}
+		}
+
+		sort.SliceStable(next, func(i, j int) bool {
+			return next[i].score > next[j].score
+		})
+		if len(next) > n*beamFactor {
+			next = next[:n*beamFactor]
+		}
+		beam = next
+	}
+
+	if len(beam) > n {
+		beam = beam[:n]
+	}
+
+	hypotheses := make([]Hypothesis, len(beam))
+	for i, state := range beam {
+		hypotheses[i] = Hypothesis{Phrases: p.hypothesisPhrases(state.last), Score: state.score}
+	}
+
+	return hypotheses, nil
+}
+
+type hypothesisState struct {
+	last  *hypothesisNode
+	score float64
+}
+
+// hypothesisNode is a token of a hypothesis, linked to the previous token.
+// The prefixes of the hypotheses are shared between them. language is the
+// language of the phrase which ends in the token.
+type hypothesisNode struct {
+	tok      Token
+	language Language
+	joined   bool
+	prev     *hypothesisNode
+}
+
+// hypothesisPhrases builds the phrases of a hypothesis from its last node.
+func (p *Processor) hypothesisPhrases(last *hypothesisNode) []Phrase {
+	nodes := []*hypothesisNode{}
+	for node := last; node != nil; node = node.prev {
+		nodes = append(nodes, node)
+	}
+
+	phrases := []Phrase{}
+	b := phraseBuilder{p: p, expander: p.Expander}
+	emit := func(phrase Phrase) {
+		phrases = append(phrases, phrase)
+	}
+
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		if node.tok.Type != WordToken {
+			b.flush(emit)
+			emit(Phrase{Tokens: []Token{node.tok}})
+			continue
+		}
+		if !node.joined {
+			b.flush(emit)
+		}
+		b.current = append(b.current, node.tok)
+	}
+	b.flush(emit)
+
+	return phrases
+}
@@ -0,0 +1,41 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorProcessNBest(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar", "bass guitar", "base", "guitar"})
+
+	hypotheses, err := processor.ProcessNBest(strings.NewReader("base guitarr"), 3)
+	if !NoError(t, err) || !Len(t, hypotheses, 3) {
+		return
+	}
+
+	got := make([][]string, len(hypotheses))
+	for i, h := range hypotheses {
+		for _, phrase := range h.Phrases {
+			got[i] = append(got[i], phrase.String())
+		}
+		if i > 0 {
+			GreaterOrEqual(t, hypotheses[i-1].Score, h.Score)
+		}
+	}
+
+	Equal(t, [][]string{
+		{"bass guitar"},
+		{"bass", "guitar"},
+		{"base", "guitar"},
+	}, got)
+
+	hypotheses, err = processor.ProcessNBest(strings.NewReader(""), 3)
+	if NoError(t, err) {
+		Equal(t, []ptpp.Hypothesis{{Phrases: []ptpp.Phrase{}}}, hypotheses)
+	}
+}
@@ -0,0 +1,609 @@
+package ptpp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	numberZero = iota
+	numberUnit
+	numberTeen
+	numberTen
+	numberHundreds
+	numberHundred
+	numberScale
+	numberDigits
+)
+
+const (
+	numberAnyLanguage = iota
+	numberPersian
+	numberEnglish
+)
+
+type numberWord struct {
+	kind     int
+	value    int64
+	language int
+	ordinal  bool
+}
+
+var (
+	persianNumberWords = map[string]numberWord{
+		"صفر":     {kind: numberZero, value: 0},
+		"یک":      {kind: numberUnit, value: 1},
+		"دو":      {kind: numberUnit, value: 2},
+		"سه":      {kind: numberUnit, value: 3},
+		"چهار":    {kind: numberUnit, value: 4},
+		"پنج":     {kind: numberUnit, value: 5},
+		"شش":      {kind: numberUnit, value: 6},
+		"شیش":     {kind: numberUnit, value: 6},
+		"هفت":     {kind: numberUnit, value: 7},
+		"هشت":     {kind: numberUnit, value: 8},
+		"نه":      {kind: numberUnit, value: 9},
+		"ده":      {kind: numberTeen, value: 10},
+		"یازده":   {kind: numberTeen, value: 11},
+		"دوازده":  {kind: numberTeen, value: 12},
+		"سیزده":   {kind: numberTeen, value: 13},
+		"چهارده":  {kind: numberTeen, value: 14},
+		"پانزده":  {kind: numberTeen, value: 15},
+		"پونزده":  {kind: numberTeen, value: 15},
+		"شانزده":  {kind: numberTeen, value: 16},
+		"شونزده":  {kind: numberTeen, value: 16},
+		"هفده":    {kind: numberTeen, value: 17},
+		"هجده":    {kind: numberTeen, value: 18},
+		"هیجده":   {kind: numberTeen, value: 18},
+		"نوزده":   {kind: numberTeen, value: 19},
+		"بیست":    {kind: numberTen, value: 20},
+		"سی":      {kind: numberTen, value: 30},
+		"چهل":     {kind: numberTen, value: 40},
+		"پنجاه":   {kind: numberTen, value: 50},
+		"شصت":     {kind: numberTen, value: 60},
+		"هفتاد":   {kind: numberTen, value: 70},
+		"هشتاد":   {kind: numberTen, value: 80},
+		"نود":     {kind: numberTen, value: 90},
+		"صد":      {kind: numberHundred, value: 100},
+		"یکصد":    {kind: numberHundreds, value: 100},
+		"دویست":   {kind: numberHundreds, value: 200},
+		"سیصد":    {kind: numberHundreds, value: 300},
+		"چهارصد":  {kind: numberHundreds, value: 400},
+		"پانصد":   {kind: numberHundreds, value: 500},
+		"ششصد":    {kind: numberHundreds, value: 600},
+		"هفتصد":   {kind: numberHundreds, value: 700},
+		"هشتصد":   {kind: numberHundreds, value: 800},
+		"نهصد":    {kind: numberHundreds, value: 900},
+		"هزار":    {kind: numberScale, value: 1e3},
+		"میلیون":  {kind: numberScale, value: 1e6},
+		"میلیارد": {kind: numberScale, value: 1e9},
+		"تریلیون": {kind: numberScale, value: 1e12},
+	}
+
+	englishNumberWords = map[string]numberWord{
+		"zero":        {kind: numberZero, value: 0},
+		"one":         {kind: numberUnit, value: 1},
+		"two":         {kind: numberUnit, value: 2},
+		"three":       {kind: numberUnit, value: 3},
+		"four":        {kind: numberUnit, value: 4},
+		"five":        {kind: numberUnit, value: 5},
+		"six":         {kind: numberUnit, value: 6},
+		"seven":       {kind: numberUnit, value: 7},
+		"eight":       {kind: numberUnit, value: 8},
+		"nine":        {kind: numberUnit, value: 9},
+		"ten":         {kind: numberTeen, value: 10},
+		"eleven":      {kind: numberTeen, value: 11},
+		"twelve":      {kind: numberTeen, value: 12},
+		"thirteen":    {kind: numberTeen, value: 13},
+		"fourteen":    {kind: numberTeen, value: 14},
+		"fifteen":     {kind: numberTeen, value: 15},
+		"sixteen":     {kind: numberTeen, value: 16},
+		"seventeen":   {kind: numberTeen, value: 17},
+		"eighteen":    {kind: numberTeen, value: 18},
+		"nineteen":    {kind: numberTeen, value: 19},
+		"twenty":      {kind: numberTen, value: 20},
+		"thirty":      {kind: numberTen, value: 30},
+		"forty":       {kind: numberTen, value: 40},
+		"fifty":       {kind: numberTen, value: 50},
+		"sixty":       {kind: numberTen, value: 60},
+		"seventy":     {kind: numberTen, value: 70},
+		"eighty":      {kind: numberTen, value: 80},
+		"ninety":      {kind: numberTen, value: 90},
+		"hundred":     {kind: numberHundred, value: 100},
+		"thousand":    {kind: numberScale, value: 1e3},
+		"million":     {kind: numberScale, value: 1e6},
+		"billion":     {kind: numberScale, value: 1e9},
+		"trillion":    {kind: numberScale, value: 1e12},
+		"quadrillion": {kind: numberScale, value: 1e15},
+		"quintillion": {kind: numberScale, value: 1e18},
+	}
+
+	englishOrdinalWords = map[string]string{
+		"first":       "one",
+		"second":      "two",
+		"third":       "three",
+		"fourth":      "four",
+		"fifth":       "five",
+		"sixth":       "six",
+		"seventh":     "seven",
+		"eighth":      "eight",
+		"ninth":       "nine",
+		"tenth":       "ten",
+		"eleventh":    "eleven",
+		"twelfth":     "twelve",
+		"thirteenth":  "thirteen",
+		"fourteenth":  "fourteen",
+		"fifteenth":   "fifteen",
+		"sixteenth":   "sixteen",
+		"seventeenth": "seventeen",
+		"eighteenth":  "eighteen",
+		"nineteenth":  "nineteen",
+		"twentieth":   "twenty",
+		"thirtieth":   "thirty",
+		"fortieth":    "forty",
+		"fiftieth":    "fifty",
+		"sixtieth":    "sixty",
+		"seventieth":  "seventy",
+		"eightieth":   "eighty",
+		"ninetieth":   "ninety",
+		"hundredth":   "hundred",
+		"thousandth":  "thousand",
+		"millionth":   "million",
+		"billionth":   "billion",
+		"trillionth":  "trillion",
+	}
+
+	numberConnectors = map[string]bool{
+		"و":   true,
+		"and": true,
+	}
+
+	// nonOrdinalWords are the common words which look like the ordinal forms
+	// of numbers, such as "سیم" (wire) and "دوام" (durability).
+	nonOrdinalWords = map[string]bool{
+		"سیم":   true,
+		"سیمین": true,
+		"دوام":  true,
+		"صدام":  true,
+	}
+
+	// ambiguousNumberWords are the number words which are also common words,
+	// such as "نه" (no) and the verbs "دهم" (I give) and "نهم" (I put). They
+	// are not converted in a text unless they are a part of a longer number,
+	// e.g. "بیست و نه".
+	ambiguousNumberWords = map[string]bool{
+		"نه":  true,
+		"دهم": true,
+		"نهم": true,
+	}
+
+	// negativeWords are the words which negate the following number.
+	negativeWords = map[string]bool{
+		"منفی":  true,
+		"minus": true,
+	}
+
+	numberWords = map[string]numberWord{}
+)
+
+const persianOrdinalSuffix = "ام"
+
+func init() {
+	for word, nw := range persianNumberWords {
+		nw.language = numberPersian
+		numberWords[normalizeWord(word)] = nw
+
+		if nw.kind == numberZero {
+			continue
+		}
+		if word == "سه" {
+			// "سهم" is a common word (share), so only the irregular "سوم"
+			// forms are accepted for three.
+			continue
+		}
+		nw.ordinal = true
+		for _, suffix := range []string{"م", "ام", "مین", "امین"} {
+			if !nonOrdinalWords[word+suffix] {
+				numberWords[normalizeWord(word+suffix)] = nw
+			}
+		}
+	}
+
+	third := numberWords[normalizeWord("سه")]
+	third.ordinal = true
+	numberWords[normalizeWord("سوم")] = third
+	numberWords[normalizeWord("سومین")] = third
+
+	first := numberWords[normalizeWord("یک")]
+	first.ordinal = true
+	for _, word := range []string{"اول", "اولین", "نخست", "نخستین"} {
+		numberWords[normalizeWord(word)] = first
+	}
+
+	for word, nw := range englishNumberWords {
+		nw.language = numberEnglish
+		numberWords[word] = nw
+	}
+	for word, cardinal := range englishOrdinalWords {
+		nw := numberWords[cardinal]
+		nw.ordinal = true
+		numberWords[word] = nw
+	}
+}
+
+// numberParser accumulates a spelled-out number word by word.
+type numberParser struct {
+	total     int64
+	group     int64
+	lastScale int64
+	words     int
+	language  int
+	done      bool
+}
+
+func (np *numberParser) value() int64 {
+	return np.total + np.group
+}
+
+// lookupNumberWord finds a number word, or a group of up to three digits.
+func lookupNumberWord(word string) (numberWord, bool) {
+	if nw, ok := numberWords[word]; ok {
+		return nw, true
+	}
+
+	n, err := strconv.ParseInt(word, 10, 64)
+	if err != nil || !isDigits(word) || n >= 1000 {
+		return numberWord{}, false
+	}
+	return numberWord{kind: numberDigits, value: n}, true
+}
+
+// compoundScale combines a scale with a larger scale following it, such as
+// "هزار تریلیون", which PersianNumber uses for the scales above trillion.
+func compoundScale(nw, next numberWord) (numberWord, bool) {
+	if nw.kind != numberScale || next.kind != numberScale || nw.ordinal || next.value <= nw.value {
+		return nw, false
+	}
+	if next.value > math.MaxInt64/nw.value {
+		return nw, false
+	}
+	next.value *= nw.value
+	return next, true
+}
+
+// accept adds a number word to the number and reports whether the word is a
+// valid continuation of it. The number is unchanged if it is not.
+func (np *numberParser) accept(nw numberWord) bool {
+	if np.done {
+		return false
+	}
+
+	next := *np
+	if !next.add(nw) {
+		return false
+	}
+	*np = next
+	return true
+}
+
+func (np *numberParser) add(nw numberWord) bool {
+	if nw.language != numberAnyLanguage && np.language != numberAnyLanguage && nw.language != np.language {
+		return false
+	}
+
+	switch nw.kind {
+	case numberZero:
+		if np.words > 0 {
+			return false
+		}
+		np.done = true
+	case numberUnit:
+		if r := np.group % 100; r != 0 && (r < 20 || r%10 != 0) {
+			return false
+		}
+		np.group += nw.value
+	case numberTeen, numberTen:
+		if np.group%100 != 0 {
+			return false
+		}
+		np.group += nw.value
+	case numberHundreds, numberDigits:
+		if np.group != 0 {
+			return false
+		}
+		np.group += nw.value
+	case numberHundred:
+		if np.group >= 10 {
+			return false
+		}
+		if np.group == 0 {
+			np.group = 1
+		}
+		np.group *= nw.value
+	case numberScale:
+		if np.lastScale != 0 && nw.value >= np.lastScale {
+			return false
+		}
+		if np.group == 0 {
+			if np.total != 0 {
+				return false
+			}
+			np.group = 1
+		}
+		if np.group > (math.MaxInt64-np.total)/nw.value {
+			return false
+		}
+		np.total += np.group * nw.value
+		np.group = 0
+		np.lastScale = nw.value
+	}
+
+	if nw.language != numberAnyLanguage {
+		np.language = nw.language
+	}
+	if np.value() < 0 {
+		// The number overflows int64.
+		return false
+	}
+	np.words++
+	if nw.ordinal {
+		np.done = true
+	}
+
+	return true
+}
+
+// parseNumberWords parses the longest spelled-out number at the beginning of
+// words. It returns the number of words consumed, which is zero if words does
+// not start with a spelled-out number.
+func parseNumberWords(words []string) (int64, int) {
+	var np numberParser
+	spelled := false
+	n := 0
+
+	for n < len(words) {
+		word := words[n]
+		next := n + 1
+
+		if word == persianOrdinalSuffix && np.words > 0 && !np.done && np.language != numberEnglish {
+			np.done = true
+			n = next
+			break
+		}
+
+		if numberConnectors[word] && np.words > 0 && next < len(words) {
+			word = words[next]
+			next++
+		}
+
+		nw, ok := lookupNumberWord(word)
+		if !ok {
+			break
+		}
+		if next < len(words) {
+			if larger, ok := lookupNumberWord(words[next]); ok {
+				if combined, ok := compoundScale(nw, larger); ok {
+					nw = combined
+					next++
+				}
+			}
+		}
+		if !np.accept(nw) {
+			break
+		}
+
+		if _, ok := numberWords[word]; ok {
+			spelled = true
+		}
+		n = next
+	}
+
+	if !spelled {
+		// A run of plain digits is not converted, so that for example leading
+		// zeros are preserved.
+		return 0, 0
+	}
+
+	return np.value(), n
+}
+
+// findNumberWords is like parseNumberWords, but it does not accept a single
+// ambiguous number word, which is more likely a common word in a text.
+func findNumberWords(words []string) (int64, int) {
+	value, n := parseNumberWords(words)
+	if n == 1 && ambiguousNumberWords[words[0]] {
+		return 0, 0
+	}
+	return value, n
+}
+
+func isDigits(word string) bool {
+	for _, ch := range word {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return word != ""
+}
+
+func convertNumbers(words []string) []string {
+	converted := make([]string, 0, len(words))
+
+	for i := 0; i < len(words); {
+		value, n := findNumberWords(words[i:])
+		if n == 0 {
+			converted = append(converted, words[i])
+			i++
+			continue
+		}
+
+		converted = append(converted, strconv.FormatInt(value, 10))
+		i += n
+	}
+
+	return converted
+}
+
+// ParseNumber parses a Persian or English spelled-out number, such as "سی و
+// پنج", "twenty five", the ordinal "سوم" or the negative "منفی هفت". It is the
+// inverse of PersianNumber and EnglishNumber for every number except
+// math.MinInt64, whose absolute value overflows int64. If the whole phrase is
+// not a number, ok is false.
+func ParseNumber(phrase string) (n int64, ok bool) {
+	words, _ := readWords(strings.NewReader(phrase))
+
+	negative := len(words) > 0 && negativeWords[words[0]]
+	if negative {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	var value int64
+	if len(words) == 1 && isDigits(words[0]) {
+		var err error
+		if value, err = strconv.ParseInt(words[0], 10, 64); err != nil {
+			return 0, false
+		}
+	} else {
+		var consumed int
+		if value, consumed = parseNumberWords(words); consumed != len(words) {
+			return 0, false
+		}
+	}
+
+	if negative {
+		if value == 0 {
+			return 0, false
+		}
+		value = -value
+	}
+
+	return value, true
+}
+
+// ConvertNumbers replaces the spelled-out numbers in a text with digits.
+func ConvertNumbers(text string) string {
+	words, _ := readWords(strings.NewReader(text))
+	return strings.Join(convertNumbers(words), " ")
+}
+
+var (
+	persianUnits    = []string{"", "یک", "دو", "سه", "چهار", "پنج", "شش", "هفت", "هشت", "نه"}
+	persianTeens    = []string{"ده", "یازده", "دوازده", "سیزده", "چهارده", "پانزده", "شانزده", "هفده", "هجده", "نوزده"}
+	persianTens     = []string{"", "", "بیست", "سی", "چهل", "پنجاه", "شصت", "هفتاد", "هشتاد", "نود"}
+	persianHundreds = []string{"", "صد", "دویست", "سیصد", "چهارصد", "پانصد", "ششصد", "هفتصد", "هشتصد", "نهصد"}
+	persianScales   = []string{"", "هزار", "میلیون", "میلیارد", "تریلیون", "هزار تریلیون", "میلیون تریلیون"}
+
+	englishUnits  = []string{"", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+	englishTeens  = []string{"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	englishTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishScales = []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion"}
+)
+
+// PersianNumber spells out a number in Persian.
+func PersianNumber(n int64) string {
+	if n == 0 {
+		return "صفر"
+	}
+
+	groups := []string{}
+	for scale, g := range numberGroups(n) {
+		if g == 0 {
+			continue
+		}
+
+		parts := []string{}
+		if h := g / 100; h > 0 {
+			parts = append(parts, persianHundreds[h])
+		}
+		if r := g % 100; r >= 10 && r < 20 {
+			parts = append(parts, persianTeens[r-10])
+		} else {
+			if t := r / 10; t > 0 {
+				parts = append(parts, persianTens[t])
+			}
+			if u := r % 10; u > 0 {
+				parts = append(parts, persianUnits[u])
+			}
+		}
+
+		group := strings.Join(parts, " و ")
+		if scale > 0 {
+			if g == 1 && scale == 1 {
+				group = persianScales[scale]
+			} else {
+				group += " " + persianScales[scale]
+			}
+		}
+		groups = append([]string{group}, groups...)
+	}
+
+	result := strings.Join(groups, " و ")
+	if n < 0 {
+		result = "منفی " + result
+	}
+
+	return result
+}
+
+// EnglishNumber spells out a number in English.
+func EnglishNumber(n int64) string {
+	if n == 0 {
+		return "zero"
+	}
+
+	groups := []string{}
+	for scale, g := range numberGroups(n) {
+		if g == 0 {
+			continue
+		}
+
+		parts := []string{}
+		if h := g / 100; h > 0 {
+			parts = append(parts, englishUnits[h]+" hundred")
+		}
+		if r := g % 100; r >= 10 && r < 20 {
+			parts = append(parts, englishTeens[r-10])
+		} else if r > 0 {
+			t, u := r/10, r%10
+			switch {
+			case t > 0 && u > 0:
+				parts = append(parts, englishTens[t]+"-"+englishUnits[u])
+			case t > 0:
+				parts = append(parts, englishTens[t])
+			default:
+				parts = append(parts, englishUnits[u])
+			}
+		}
+		if scale > 0 {
+			parts = append(parts, englishScales[scale])
+		}
+		groups = append([]string{strings.Join(parts, " ")}, groups...)
+	}
+
+	result := strings.Join(groups, " ")
+	if n < 0 {
+		result = "minus " + result
+	}
+
+	return result
+}
+
+// numberGroups splits the absolute value of n into groups of three digits,
+// starting from the least significant group.
+func numberGroups(n int64) []int {
+	u := uint64(n)
+	if n < 0 {
+		u = uint64(-n)
+	}
+
+	groups := []int{}
+	for u > 0 {
+		groups = append(groups, int(u%1000))
+		u /= 1000
+	}
+
+	return groups
+}
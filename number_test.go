@@ -0,0 +1,110 @@
+package ptpp_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		phrase string
+		want   int64
+		ok     bool
+	}{
+		{"سی و پنج", 35, true},
+		{"twenty five", 25, true},
+		{"twenty-five", 25, true},
+		{"دو هزار و پانصد", 2500, true},
+		{"یک میلیون و دویست هزار", 1200000, true},
+		{"one hundred and five", 105, true},
+		{"صد هزار", 100000, true},
+		{"۲ هزار", 2000, true},
+		{"سوم", 3, true},
+		{"بیست و یکم", 21, true},
+		{"سی ام", 30, true},
+		{"twenty first", 21, true},
+		{"hundredth", 100, true},
+		{"42", 42, true},
+		{"نه", 9, true},
+		{"منفی هفت", -7, true},
+		{"minus twenty one", -21, true},
+		{"منفی", 0, false},
+		{"منفی منفی هفت", 0, false},
+		{"منفی صفر", 0, false},
+		{"دو هزار تریلیون", 2e15, true},
+		{"three quadrillion", 3e15, true},
+		{"نهصد میلیون تریلیون", 0, false},
+		{"سیم", 0, false},
+		{"five five", 0, false},
+		{"twenty پنج", 0, false},
+		{"guitar", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			got, ok := ptpp.ParseNumber(tt.phrase)
+			Equal(t, tt.ok, ok)
+			Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertNumbers(t *testing.T) {
+	Equal(t, "قیمت 35000 تومان", ptpp.ConvertNumbers("قیمت سی و پنج هزار تومان"))
+	Equal(t, "iphone 11 pro", ptpp.ConvertNumbers("iPhone eleven Pro"))
+	Equal(t, "room 05", ptpp.ConvertNumbers("room 05"))
+	Equal(t, "سیم برق", ptpp.ConvertNumbers("سیم برق"))
+	Equal(t, "نه ممنون", ptpp.ConvertNumbers("نه ممنون"))
+	Equal(t, "29 ممنون", ptpp.ConvertNumbers("بیست و نه ممنون"))
+	Equal(t, "30 برق", ptpp.ConvertNumbers("سی ام برق"))
+	Equal(t, "می خواهم انجام دهم", ptpp.ConvertNumbers("می خواهم انجام دهم"))
+	Equal(t, "کتاب را اینجا نهم", ptpp.ConvertNumbers("کتاب را اینجا نهم"))
+	Equal(t, "روز 29", ptpp.ConvertNumbers("روز بیست و نهم"))
+}
+
+func TestSpellNumber(t *testing.T) {
+	tests := []struct {
+		n       int64
+		persian string
+		english string
+	}{
+		{0, "صفر", "zero"},
+		{35, "سی و پنج", "thirty-five"},
+		{1000, "هزار", "one thousand"},
+		{2500, "دو هزار و پانصد", "two thousand five hundred"},
+		{1200013, "یک میلیون و دویست هزار و سیزده", "one million two hundred thousand thirteen"},
+		{-7, "منفی هفت", "minus seven"},
+		{5230e12, "پنج هزار تریلیون و دویست و سی تریلیون", "five quadrillion two hundred thirty trillion"},
+		{math.MaxInt64, ptpp.PersianNumber(math.MaxInt64), ptpp.EnglishNumber(math.MaxInt64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.english, func(t *testing.T) {
+			Equal(t, tt.persian, ptpp.PersianNumber(tt.n))
+			Equal(t, tt.english, ptpp.EnglishNumber(tt.n))
+
+			for _, spelled := range []string{tt.persian, tt.english} {
+				got, ok := ptpp.ParseNumber(spelled)
+				if True(t, ok) {
+					Equal(t, tt.n, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessorConvertNumbers(t *testing.T) {
+	processor := ptpp.Processor{ConvertNumbers: true}
+	processor.Train([]string{"35 mm lens"})
+
+	got, err := processor.Process(strings.NewReader("thirty five mm lens"))
+	NoError(t, err)
+	Equal(t, []string{"35 mm lens"}, got)
+
+	got, err = processor.Process(strings.NewReader("سیم برق"))
+	NoError(t, err)
+	Equal(t, []string{"سیم", "برق"}, got)
+}
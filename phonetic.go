@@ -0,0 +1,186 @@
+package ptpp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// persianPhonetics maps the Persian letters with the same pronunciation into
+// a single letter.
+var persianPhonetics = map[rune]rune{
+	'ذ': 'ز', 'ض': 'ز', 'ظ': 'ز',
+	'ث': 'س', 'ص': 'س',
+	'ط': 'ت',
+	'ح': 'ه',
+	'غ': 'ق',
+	'ع': 'ا', 'ء': 'ا', 'أ': 'ا', 'إ': 'ا', 'ئ': 'ا', 'ؤ': 'ا', 'آ': 'ا',
+}
+
+// PhoneticKey computes a key of a word which is shared by the words with the
+// same pronunciation. Persian homophone letters, such as "ذ", "ز", "ض" and
+// "ظ", are unified. English words are encoded by a simplified Metaphone
+// algorithm, so that e.g. "phone" and "fone" have the same key.
+func PhoneticKey(word string) string {
+	word = normalizeWord(word)
+
+	first, _ := utf8.DecodeRuneInString(word)
+	switch {
+	case isEnglishLetter(first):
+		return englishPhoneticKey(word)
+	case isArabicOrFarsiLetter(first):
+		return persianPhoneticKey(word)
+	}
+
+	return word
+}
+
+func persianPhoneticKey(word string) string {
+	sb := strings.Builder{}
+	var last rune
+	for _, r := range word {
+		if p, ok := persianPhonetics[r]; ok {
+			r = p
+		}
+		if r != last {
+			sb.WriteRune(r)
+			last = r
+		}
+	}
+	return sb.String()
+}
+
+func englishPhoneticKey(word string) string {
+	w := strings.ToLower(word)
+
+	for _, silent := range []string{"kn", "gn", "pn", "wr", "ae"} {
+		if strings.HasPrefix(w, silent) {
+			w = w[1:]
+			break
+		}
+	}
+	switch {
+	case strings.HasPrefix(w, "x"):
+		w = "s" + w[1:]
+	case strings.HasPrefix(w, "wh"):
+		w = "w" + w[2:]
+	}
+
+	at := func(i int) byte {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+
+	sb := strings.Builder{}
+	for i := 0; i < len(w); i++ {
+		c, next := w[i], at(i+1)
+
+		code := ""
+		switch c {
+		case 'a', 'e', 'i', 'o', 'u':
+			// Vowels are only kept at the beginning of the word.
+			if i == 0 {
+				code = "a"
+			}
+		case 'b':
+			// "b" is silent after "m" at the end of the word, e.g. "lamb".
+			if !(i == len(w)-1 && at(i-1) == 'm') {
+				code = "p"
+			}
+		case 'c':
+			switch {
+			case next == 'h':
+				code = "x"
+				i++
+			case isFrontVowel(next):
+				code = "s"
+			default:
+				code = "k"
+			}
+		case 'd':
+			if next == 'g' && isFrontVowel(at(i+2)) {
+				code = "j"
+				i++
+			} else {
+				code = "t"
+			}
+		case 'g':
+			switch {
+			case next == 'h':
+				// "gh" is silent unless it is followed by a vowel.
+				if isVowel(at(i + 2)) {
+					code = "k"
+				}
+				i++
+			case next == 'n' && i+2 == len(w):
+				// "g" is silent in a final "gn", e.g. "sign".
+			case isFrontVowel(next):
+				code = "j"
+			default:
+				code = "k"
+			}
+		case 'h':
+			if isVowel(next) && !strings.ContainsRune("cgpst", rune(at(i-1))) {
+				code = "h"
+			}
+		case 'p':
+			if next == 'h' {
+				code = "f"
+				i++
+			} else {
+				code = "p"
+			}
+		case 'q':
+			code = "k"
+		case 's':
+			switch {
+			case next == 'h':
+				code = "x"
+				i++
+			case next == 'i' && (at(i+2) == 'a' || at(i+2) == 'o'):
+				code = "x"
+			default:
+				code = "s"
+			}
+		case 't':
+			switch {
+			case next == 'h':
+				code = "0"
+				i++
+			case next == 'i' && (at(i+2) == 'a' || at(i+2) == 'o'):
+				code = "x"
+			default:
+				code = "t"
+			}
+		case 'v':
+			code = "f"
+		case 'w', 'y':
+			if isVowel(next) {
+				code = string(c)
+			}
+		case 'x':
+			code = "ks"
+		case 'z':
+			code = "s"
+		default:
+			code = string(c)
+		}
+
+		for j := 0; j < len(code); j++ {
+			if sb.Len() == 0 || code[j] != sb.String()[sb.Len()-1] {
+				sb.WriteByte(code[j])
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func isVowel(c byte) bool {
+	return c == 'a' || c == 'e' || c == 'i' || c == 'o' || c == 'u'
+}
+
+func isFrontVowel(c byte) bool {
+	return c == 'e' || c == 'i' || c == 'y'
+}
@@ -0,0 +1,55 @@
+package ptpp_test
+
+import (
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestPhoneticKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"phone", "fone"},
+		{"night", "nite"},
+		{"Knight", "nite"},
+		{"photograph", "fotograf"},
+		{"science", "sience"},
+		{"xylophone", "zylofone"},
+		{"ظرف", "زرف"},
+		{"ذرت", "زرط"},
+		{"طلا", "تلا"},
+		{"صابون", "سابون"},
+		{"حلوا", "هلوا"},
+		{"عسل", "اسل"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a, func(t *testing.T) {
+			Equal(t, ptpp.PhoneticKey(tt.a), ptpp.PhoneticKey(tt.b))
+		})
+	}
+
+	NotEqual(t, ptpp.PhoneticKey("bass"), ptpp.PhoneticKey("guitar"))
+	NotEqual(t, ptpp.PhoneticKey("سر"), ptpp.PhoneticKey("زر"))
+	Equal(t, "123", ptpp.PhoneticKey("123"))
+}
+
+func TestDefaultSpellCheckerPhonetic(t *testing.T) {
+	var spellChecker ptpp.DefaultSpellChecker
+	spellChecker.Train([]string{"night", "photograph", "ظرف"})
+
+	Equal(t, []string{"nite"}, spellChecker.Check("nite"))
+
+	spellChecker.Phonetic = true
+	Equal(t, []string{"night"}, spellChecker.Check("nite"))
+	Equal(t, []string{"photograph"}, spellChecker.Check("fotograf"))
+	Equal(t, []string{"ظرف"}, spellChecker.Check("ذرف"))
+
+	spellChecker.Train([]string{"knight"})
+	ElementsMatch(t, []string{"night", "knight"}, spellChecker.Check("nite"))
+
+	spellChecker.Remove("night")
+	Equal(t, []string{"knight"}, spellChecker.Check("nite"))
+}
@@ -2,13 +2,20 @@ package ptpp
 
 import (
 	"archive/zip"
-	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // LoadSaver denotes an object that can store and restore its state.
@@ -32,6 +39,39 @@ type SpellChecker interface {
 	Train(words []string)
 }
 
+// SpellUntrainer is a SpellChecker which can revert its training.
+type SpellUntrainer interface {
+
+	// Untrain removes the contributions of a list of words from the
+	// suggestion model.
+	Untrain(words []string)
+}
+
+// ContextSpellChecker is a SpellChecker which supports cancellation, e.g. a
+// spell-checker backed by a remote service. The processor prefers these
+// methods over the ones of SpellChecker, except that it prefers CheckScored
+// over CheckContext for the spell-checkers which implement both.
+type ContextSpellChecker interface {
+
+	// CheckContext is like Check, but it returns ctx.Err() or any other error
+	// which prevents the check.
+	CheckContext(ctx context.Context, word string) ([]string, error)
+
+	// TrainContext is like Train, but it returns ctx.Err() or any other error
+	// which prevents the training.
+	TrainContext(ctx context.Context, words []string) error
+}
+
+// ContextScoringSpellChecker is a ScoringSpellChecker which supports
+// cancellation. The processor prefers this method over CheckScored and
+// CheckContext.
+type ContextScoringSpellChecker interface {
+
+	// CheckScoredContext is like CheckScored, but it returns ctx.Err() or any
+	// other error which prevents the check.
+	CheckScoredContext(ctx context.Context, word string) ([]Suggestion, error)
+}
+
 // SemanticMatcher provides selection of the best suggestion by its context.
 type SemanticMatcher interface {
 
@@ -44,6 +84,27 @@ type SemanticMatcher interface {
 	Train(context, word string)
 }
 
+// ContextSemanticMatcher is a SemanticMatcher which supports cancellation.
+// The processor prefers these methods over the ones of SemanticMatcher.
+type ContextSemanticMatcher interface {
+
+	// MatchContext is like Match, but it returns ctx.Err() or any other error
+	// which prevents the match.
+	MatchContext(ctx context.Context, context string, suggestions []string) (best string, matched bool, err error)
+
+	// TrainContext is like Train, but it returns ctx.Err() or any other error
+	// which prevents the training.
+	TrainContext(ctx context.Context, context, word string) error
+}
+
+// SemanticUntrainer is a SemanticMatcher which can revert its training.
+type SemanticUntrainer interface {
+
+	// Untrain removes the contribution of a word and its context from the
+	// semantic model.
+	Untrain(context, word string)
+}
+
 // Processor is the Persian text preprocessor.
 type Processor struct {
 
@@ -55,10 +116,73 @@ type Processor struct {
 	// this field is nil, the preprocessor will use DefaultSemanticMatcher.
 	SemanticMatcher SemanticMatcher
 
-	mutex sync.Mutex
+	// Languages are the models of specific languages. The words of these
+	// languages, as identified by the tokenizer, are spell-checked and matched
+	// by the models of their language, and the other words by SpellChecker and
+	// SemanticMatcher. Each model is stored in separate entries of the model
+	// file. The map is shared with the processes in progress, so it should be
+	// replaced rather than modified once the processor is in use.
+	Languages map[Language]LanguageModel
+
+	// ProtectedWords are the words which are never corrected and never used
+	// as a correction for other words, such as brand names. If this field is
+	// nil, the preprocessor will use an empty set.
+	ProtectedWords *WordSet
+
+	// Rules are the rules which map source phrases of the input into target
+	// phrases before spell-checking. If this field is nil, the preprocessor
+	// will use an empty set.
+	Rules *RuleSet
+
+	// MinConfidence is the minimum confidence for accepting a correction. If
+	// no suggestion for a misspelled word reaches this confidence, the word is
+	// kept as is and marked as Unknown. It is only effective if SpellChecker
+	// implements ScoringSpellChecker.
+	MinConfidence float64
+
+	// ConvertNumbers enables the conversion of spelled-out numbers, such as
+	// "سی و پنج" or "thirty five", into digits before spell-checking.
+	ConvertNumbers bool
+
+	// RecognizeDates enables the recognition of Jalali, Gregorian and Hijri
+	// dates, such as "۱۴۰۲/۰۵/۱۰" or "۱۰ مرداد ۱۴۰۲". Each recognized date is
+	// emitted as a single phrase in the YYYY-MM-DD form of DateCalendar.
+	RecognizeDates bool
+
+	// DateCalendar is the calendar of the recognized dates in the output.
+	DateCalendar Calendar
+
+	// RecognizeEntities enables the recognition of email addresses, URLs,
+	// phone numbers and hashtags. Each recognized entity is protected from
+	// spell-checking and emitted as a single phrase.
+	RecognizeEntities bool
+
+	// Expander finds the synonyms of the extracted phrases of words, which are
+	// attached to the phrases returned by ProcessPhrases. If this field is
+	// nil, the phrases are not expanded.
+	Expander Expander
+
+	// SplitLanguages prevents joining the words of different languages, e.g.
+	// an English word and a Persian one, into a phrase, both in processing
+	// and in training. Numbers can be joined with the words of any language.
+	SplitLanguages bool
+
+	training TrainingInfo
+	mutex    sync.Mutex
+}
+
+// models is a snapshot of the components of a processor, which is used
+// throughout a single operation, so that the components can be swapped
+// concurrently.
+type models struct {
+	spellChecker    SpellChecker
+	semanticMatcher SemanticMatcher
+	protectedWords  *WordSet
+	rules           *RuleSet
+	languages       map[Language]LanguageModel
 }
 
-func (p *Processor) ensureFields() {
+func (p *Processor) ensureFields() models {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
@@ -68,60 +192,515 @@ func (p *Processor) ensureFields() {
 	if p.SemanticMatcher == nil {
 		p.SemanticMatcher = &DefaultSemanticMatcher{}
 	}
+	if p.ProtectedWords == nil {
+		p.ProtectedWords = &WordSet{}
+	}
+	if p.Rules == nil {
+		p.Rules = &RuleSet{}
+	}
+
+	for _, lm := range p.Languages {
+		if lm.SpellChecker == nil || lm.SemanticMatcher == nil {
+			p.Languages = withDefaultModels(p.Languages)
+			break
+		}
+	}
+
+	return models{
+		spellChecker:    p.SpellChecker,
+		semanticMatcher: p.SemanticMatcher,
+		protectedWords:  p.ProtectedWords,
+		rules:           p.Rules,
+		languages:       p.Languages,
+	}
+}
+
+// Training returns the training metadata of the processor, which is stored
+// in the manifest of the saved models.
+func (p *Processor) Training() TrainingInfo {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.training
 }
 
 // Train trains the preprocessing model with a list of phrases.
 func (p *Processor) Train(phrases []string) {
-	p.ensureFields()
+	p.TrainContext(context.Background(), phrases)
+}
+
+// TrainContext is like Train, but it stops when ctx is done and returns
+// ctx.Err(). The phrases trained before the cancellation are kept in the
+// model and counted in its training metadata.
+func (p *Processor) TrainContext(ctx context.Context, phrases []string) error {
+	m := p.ensureFields()
+
+	for _, phrase := range phrases {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for _, run := range p.readTrainingWords(m, phrase) {
+			if err := m.train(ctx, run); err != nil {
+				return err
+			}
+		}
+
+		p.mutex.Lock()
+		p.training.Phrases++
+		p.training.UpdatedAt = time.Now().UTC()
+		p.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// train trains the components with a run of consecutive words. The words,
+// and the pairs of words, are trained into the models of the language of the
+// word.
+func (m models) train(ctx context.Context, run []Token) error {
+	languages, words := m.groupWords(run)
+	for _, language := range languages {
+		lm := m.language(language)
+		if sc, ok := lm.spellChecker.(ContextSpellChecker); ok {
+			if err := sc.TrainContext(ctx, words[language]); err != nil {
+				return err
+			}
+		} else {
+			lm.spellChecker.Train(words[language])
+		}
+	}
+
+	for i := 0; i < len(run)-1; i++ {
+		lm := m.language(run[i+1].Language)
+		sm, ok := lm.semanticMatcher.(ContextSemanticMatcher)
+		if !ok {
+			lm.semanticMatcher.Train(run[i].Text, run[i+1].Text)
+			continue
+		}
+		if err := sm.TrainContext(ctx, run[i].Text, run[i+1].Text); err != nil {
+			return err
+		}
+	}
 
+	return nil
+}
+
+// Untrain removes the contributions of a list of phrases from the
+// preprocessing model, e.g. phrases with misspellings which leaked into the
+// training data. The components must implement SpellUntrainer and
+// SemanticUntrainer. The phrases without words are not subtracted from the
+// count of trained phrases in the training metadata. As the phrases are not
+// stored, the count is approximate if phrases which were never trained are
+// untrained.
+func (p *Processor) Untrain(phrases []string) error {
+	m := p.ensureFields()
+
+	for _, lm := range m.all() {
+		if _, ok := lm.spellChecker.(SpellUntrainer); !ok {
+			return fmt.Errorf("ptpp: cannot untrain spell-checker of type %T", lm.spellChecker)
+		}
+		if _, ok := lm.semanticMatcher.(SemanticUntrainer); !ok {
+			return fmt.Errorf("ptpp: cannot untrain semantic matcher of type %T", lm.semanticMatcher)
+		}
+	}
+
+	untrained := 0
 	for _, phrase := range phrases {
-		words, _ := readWords(strings.NewReader(phrase))
-		p.SpellChecker.Train(words)
-		for i := 0; i < len(words)-1; i++ {
-			p.SemanticMatcher.Train(words[i], words[i+1])
+		runs := p.readTrainingWords(m, phrase)
+		if len(runs) > 0 {
+			untrained++
+		}
+
+		for _, run := range runs {
+			languages, words := m.groupWords(run)
+			for _, language := range languages {
+				m.language(language).spellChecker.(SpellUntrainer).Untrain(words[language])
+			}
+			for i := 0; i < len(run)-1; i++ {
+				sm := m.language(run[i+1].Language).semanticMatcher.(SemanticUntrainer)
+				sm.Untrain(run[i].Text, run[i+1].Text)
+			}
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.training.Phrases -= int64(untrained)
+	if p.training.Phrases < 0 {
+		p.training.Phrases = 0
+	}
+	p.training.UpdatedAt = time.Now().UTC()
+
+	return nil
+}
+
+// readTrainingWords splits a training phrase into runs of consecutive words.
+func (p *Processor) readTrainingWords(m models, phrase string) [][]Token {
+	return splitWords(p.tokenize(m, phrase), p.SplitLanguages)
+}
+
+// splitWords splits tokens into runs of consecutive words, which are also
+// split where the language changes if splitLanguages is set.
+func splitWords(tokens []Token, splitLanguages bool) [][]Token {
+	runs := [][]Token{}
+	words := []Token{}
+	language := NumberLanguage
+
+	for _, tok := range tokens {
+		if tok.Type == WordToken {
+			joined := true
+			if splitLanguages {
+				language, joined = joinLanguage(language, tok.Language)
+			}
+			if !joined {
+				runs = append(runs, words)
+				words = []Token{}
+				language = tok.Language
+			}
+			words = append(words, tok)
+			continue
+		}
+		language = NumberLanguage
+		if len(words) > 0 {
+			runs = append(runs, words)
+			words = []Token{}
 		}
 	}
+
+	if len(words) > 0 {
+		runs = append(runs, words)
+	}
+
+	return runs
 }
 
 // Process does the preprocessing on an input and extracts phrases.
 func (p *Processor) Process(r io.Reader) ([]string, error) {
-	p.ensureFields()
+	return p.ProcessContext(context.Background(), r)
+}
 
-	words, err := readWords(r)
+// ProcessContext is like Process, but it stops when ctx is done and returns
+// ctx.Err().
+func (p *Processor) ProcessContext(ctx context.Context, r io.Reader) ([]string, error) {
+	phrases, err := p.processPhrases(ctx, r)
 	if err != nil {
 		return nil, err
 	}
 
-	phrases := []string{}
-	currentPhrase := []string{}
+	result := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		result[i] = phrase.String()
+	}
 
-	for _, word := range words {
-		suggestions := p.SpellChecker.Check(word)
-		if len(currentPhrase) == 0 {
-			currentPhrase = append(currentPhrase, suggestions[0])
-			continue
+	return result, nil
+}
+
+// ProcessPhrases does the preprocessing on an input and extracts phrases
+// along with their typed tokens.
+func (p *Processor) ProcessPhrases(r io.Reader) ([]Phrase, error) {
+	return p.processPhrases(context.Background(), r)
+}
+
+func (p *Processor) processPhrases(ctx context.Context, r io.Reader) ([]Phrase, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m := p.ensureFields()
+	tokens, err := p.readTokens(m, r)
+	if err != nil {
+		return nil, err
+	}
+
+	phrases := []Phrase{}
+	b := phraseBuilder{p: p, m: m, expander: p.Expander}
+	emit := func(phrase Phrase) {
+		phrases = append(phrases, phrase)
+	}
+
+	for _, tok := range tokens {
+		if err := b.add(ctx, tok, emit); err != nil {
+			return nil, err
 		}
+	}
+	b.flush(emit)
 
-		context := currentPhrase[len(currentPhrase)-1]
-		best, matched := p.SemanticMatcher.Match(context, suggestions)
-		if !matched {
-			phrases = append(phrases, strings.Join(currentPhrase, " "))
-			currentPhrase = []string{best}
-		} else {
-			currentPhrase = append(currentPhrase, best)
+	return phrases, nil
+}
+
+// phraseBuilder joins the tokens into phrases. A phrase is emitted as soon as
+// a token which does not belong to it is added.
+type phraseBuilder struct {
+	p        *Processor
+	m        models
+	expander Expander
+	current  []Token
+	language Language
+}
+
+func (b *phraseBuilder) add(ctx context.Context, tok Token, emit func(Phrase)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tok.Type != WordToken {
+		b.flush(emit)
+		emit(Phrase{Tokens: []Token{tok}})
+		return nil
+	}
+
+	_, err := b.addWord(ctx, tok, emit)
+	return err
+}
+
+// addWord spell-checks a word token and adds it to the current phrase, or
+// starts a new phrase with it. It returns the spell-checked token.
+func (b *phraseBuilder) addWord(ctx context.Context, tok Token, emit func(Phrase)) (Token, error) {
+	suggestions, err := b.p.suggest(ctx, b.m, tok)
+	if err != nil {
+		return Token{}, err
+	}
+	if len(b.current) == 0 {
+		tok = spelledToken(tok, suggestions[0])
+		b.start(tok)
+		return tok, nil
+	}
+
+	if b.p.SplitLanguages {
+		language, joined := joinLanguage(b.language, tok.Language)
+		if !joined {
+			b.flush(emit)
+			tok = spelledToken(tok, suggestions[0])
+			b.start(tok)
+			return tok, nil
 		}
+		b.language = language
 	}
 
-	if len(currentPhrase) != 0 {
-		phrases = append(phrases, strings.Join(currentPhrase, " "))
+	words := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		words[i] = s.Word
 	}
 
-	return phrases, nil
+	prev := b.current[len(b.current)-1].Text
+	best, matched, err := b.m.language(tok.Language).match(ctx, prev, words)
+	if err != nil {
+		return Token{}, err
+	}
+	for _, s := range suggestions {
+		if s.Word == best {
+			tok = spelledToken(tok, s)
+			break
+		}
+	}
+
+	if !matched {
+		b.flush(emit)
+		b.start(tok)
+		return tok, nil
+	}
+	b.current = append(b.current, tok)
+
+	return tok, nil
+}
+
+// start starts a new phrase with a word token.
+func (b *phraseBuilder) start(tok Token) {
+	b.current = append(b.current, tok)
+	b.language = tok.Language
+}
+
+// flush emits the current phrase, if any.
+func (b *phraseBuilder) flush(emit func(Phrase)) {
+	if len(b.current) == 0 {
+		return
+	}
+
+	phrase := Phrase{Tokens: b.current}
+	if b.expander != nil {
+		phrase.Synonyms = b.expander.Expand(phrase.String())
+	}
+
+	emit(phrase)
+	b.current = nil
+}
+
+// suggest finds the spell suggestions for a word token. The tokens produced
+// by the rules are not spell-checked.
+func (p *Processor) suggest(ctx context.Context, m models, tok Token) ([]Suggestion, error) {
+	if tok.fixed {
+		return []Suggestion{{Word: tok.Text, Confidence: 1}}, nil
+	}
+	return p.check(ctx, m.language(tok.Language), tok.Text)
+}
+
+// check finds the spell suggestions for a word, respecting the protected
+// words and the minimum confidence.
+func (p *Processor) check(ctx context.Context, m models, word string) ([]Suggestion, error) {
+	if m.protectedWords.Has(word) {
+		return []Suggestion{{Word: word, Confidence: 1}}, nil
+	}
+
+	var scored []Suggestion
+	switch sc := m.spellChecker.(type) {
+	case ContextScoringSpellChecker:
+		checked, err := sc.CheckScoredContext(ctx, word)
+		if err != nil {
+			return nil, err
+		}
+		scored = checked
+	case ScoringSpellChecker:
+		scored = sc.CheckScored(word)
+	case ContextSpellChecker:
+		checked, err := sc.CheckContext(ctx, word)
+		if err != nil {
+			return nil, err
+		}
+		for _, suggestion := range checked {
+			scored = append(scored, Suggestion{Word: suggestion, Confidence: 1})
+		}
+	default:
+		for _, suggestion := range m.spellChecker.Check(word) {
+			scored = append(scored, Suggestion{Word: suggestion, Confidence: 1})
+		}
+	}
+
+	suggestions := []Suggestion{}
+	for _, s := range scored {
+		if s.Word == word || (s.Confidence >= p.MinConfidence && !m.protectedWords.Has(s.Word)) {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	if len(suggestions) == 0 {
+		suggestions = append(suggestions, Suggestion{Word: word})
+	}
+
+	return suggestions, nil
+}
+
+// match finds the best suggestion based on the context.
+func (m models) match(ctx context.Context, context string, suggestions []string) (string, bool, error) {
+	if sm, ok := m.semanticMatcher.(ContextSemanticMatcher); ok {
+		return sm.MatchContext(ctx, context, suggestions)
+	}
+
+	best, matched := m.semanticMatcher.Match(context, suggestions)
+	return best, matched, nil
+}
+
+// spelledToken creates the token of a word replaced by a spell suggestion.
+func spelledToken(word Token, s Suggestion) Token {
+	tok := Token{Text: s.Word, Type: WordToken, Status: word.Status, Language: word.Language, Confidence: s.Confidence}
+	switch {
+	case s.Word != word.Text:
+		tok.Status = Corrected
+	case s.Confidence == 0:
+		tok.Status = Unknown
+	}
+	return tok
+}
+
+func (p *Processor) recognizers() []recognizer {
+	recognizers := []recognizer{}
+	if p.RecognizeDates {
+		recognizers = append(recognizers, newDateRecognizers(p.DateCalendar)...)
+	}
+	if p.RecognizeEntities {
+		recognizers = append(recognizers, entityRecognizers...)
+	}
+	return recognizers
+}
+
+// readTokens reads the input and splits it into tokens.
+func (p *Processor) readTokens(m models, r io.Reader) ([]Token, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.tokenize(m, string(data)), nil
+}
+
+// tokenize splits text into tokens.
+func (p *Processor) tokenize(m models, text string) []Token {
+	spans := p.tokenSpans(m, text)
+
+	tokens := make([]Token, len(spans))
+	for i, s := range spans {
+		tokens[i] = s.token
+	}
+
+	return tokens
+}
+
+// tokenSpans splits text into tokens along with their offsets in text. The
+// spans found by the recognizers are kept as single tokens, the rest is split
+// into words, and then the rules are applied to the words.
+func (p *Processor) tokenSpans(m models, text string) []span {
+	spans := []span{}
+	offset := 0
+	for _, s := range recognize(text, p.recognizers()) {
+		spans = append(p.appendWords(spans, wordSpans(text[offset:s.start], offset)), s)
+		offset = s.end
+	}
+	spans = p.appendWords(spans, wordSpans(text[offset:], offset))
+
+	return m.rules.apply(spans)
+}
+
+// appendWords appends the spans of words to spans, converting spelled-out
+// numbers into digits if enabled.
+func (p *Processor) appendWords(spans, words []span) []span {
+	if !p.ConvertNumbers {
+		return append(spans, words...)
+	}
+
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.token.Text
+	}
+
+	for i := 0; i < len(words); {
+		value, n := findNumberWords(texts[i:])
+		if n == 0 {
+			spans = append(spans, words[i])
+			i++
+			continue
+		}
+
+		spans = append(spans, span{
+			start: words[i].start,
+			end:   words[i+n-1].end,
+			token: Token{Text: strconv.FormatInt(value, 10), Type: WordToken, Language: NumberLanguage},
+		})
+		i += n
+	}
+
+	return spans
 }
 
 func readWords(r io.Reader) ([]string, error) {
-	words := []string{}
-	br := bufio.NewReader(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := wordSpans(string(data), 0)
+	words := make([]string, len(spans))
+	for i, s := range spans {
+		words[i] = s.token.Text
+	}
+
+	return words, nil
+}
+
+// wordSpans splits text into normalized words, along with their offsets in
+// text shifted by base.
+func wordSpans(text string, base int) []span {
+	spans := []span{}
 
 	const (
 		Start   = 0
@@ -131,97 +710,206 @@ func readWords(r io.Reader) ([]string, error) {
 	)
 	state := Start
 	sb := strings.Builder{}
+	start := 0
+
+	endWord := func(end int) {
+		spans = append(spans, span{
+			start: base + start,
+			end:   base + end,
+			token: Token{Text: sb.String(), Type: WordToken, Language: wordLanguage(text[start:end])},
+		})
+		sb.Reset()
+		state = Start
+	}
 
-	for {
-		ch, _, err := br.ReadRune()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
-		}
+	for i := 0; i < len(text); {
+		ch, size := utf8.DecodeRuneInString(text[i:])
 		switch state {
 		case Start:
 			switch {
 			case isEnglishLetter(ch):
-				sb.WriteRune(normalize(ch))
 				state = English
 			case isArabicOrFarsiLetter(ch):
-				sb.WriteRune(normalize(ch))
 				state = Farsi
 			case isDigit(ch):
-				sb.WriteRune(normalize(ch))
 				state = Number
 			default:
 				// Drop unknown runes.
+				i += size
+				continue
 			}
+			start = i
+			sb.WriteRune(normalize(ch))
 		case English:
-			if isEnglishLetter(ch) {
-				sb.WriteRune(normalize(ch))
-			} else {
-				words = append(words, sb.String())
-				sb.Reset()
-				br.UnreadRune()
-				state = Start
+			if !isEnglishLetter(ch) {
+				endWord(i)
+				continue
 			}
+			sb.WriteRune(normalize(ch))
 		case Farsi:
 			if isArabicOrFarsiLetter(ch) {
 				sb.WriteRune(normalize(ch))
 			} else if isTashkil(ch) {
 				// Drop tashkils from words.
 			} else {
-				words = append(words, sb.String())
-				sb.Reset()
-				br.UnreadRune()
-				state = Start
+				endWord(i)
+				continue
 			}
 		case Number:
-			if isDigit(ch) {
-				sb.WriteRune(normalize(ch))
-			} else {
-				words = append(words, sb.String())
-				sb.Reset()
-				br.UnreadRune()
-				state = Start
+			if !isDigit(ch) {
+				endWord(i)
+				continue
 			}
+			sb.WriteRune(normalize(ch))
 		}
+		i += size
 	}
 
 	if sb.Len() > 0 {
-		words = append(words, sb.String())
+		endWord(len(text))
 	}
 
-	return words, nil
+	return spans
 }
 
 const (
 	scFileName = "sc.gob"
 	smFileName = "sm.gob"
+	pwFileName = "pw.txt"
+	rsFileName = "rules.txt"
 )
 
-// Load restores the state of the processor from filePath.
-func (p *Processor) Load(filePath string) error {
-	p.ensureFields()
+// component is a part of the processor which is stored in an entry of the
+// model file.
+type component struct {
+	name  string
+	value interface{}
 
+	// optional denotes a component which may be absent in the models saved
+	// before the introduction of the manifest.
+	optional bool
+}
+
+func (m models) components() []component {
+	components := []component{
+		{name: scFileName, value: m.spellChecker},
+		{name: smFileName, value: m.semanticMatcher},
+		{name: pwFileName, value: m.protectedWords, optional: true},
+		{name: rsFileName, value: m.rules, optional: true},
+	}
+
+	for _, language := range m.sortedLanguages() {
+		lm := m.languages[language]
+		components = append(components,
+			component{name: path.Join(language.String(), scFileName), value: lm.SpellChecker, optional: true},
+			component{name: path.Join(language.String(), smFileName), value: lm.SemanticMatcher, optional: true},
+		)
+	}
+
+	return components
+}
+
+// Load restores the state of the processor from filePath. The model is
+// validated against its manifest before any component is restored. The
+// languages of the model are added to Languages, and the model must have the
+// entries of the languages already in Languages, or MissingEntryError is
+// returned.
+func (p *Processor) Load(filePath string) error {
 	zr, err := zip.OpenReader(filePath)
 	if err != nil {
 		return err
 	}
 	defer zr.Close()
 
+	return p.loadZip(&zr.Reader)
+}
+
+// LoadFrom restores the state of the processor from a model of the given size
+// read from r.
+func (p *Processor) LoadFrom(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	return p.loadZip(zr)
+}
+
+// LoadFS restores the state of the processor from the model named name in
+// fsys, e.g. a model embedded with go:embed.
+func (p *Processor) LoadFS(fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if ra, ok := f.(io.ReaderAt); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return p.LoadFrom(ra, info.Size())
+	}
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	return p.LoadFrom(bytes.NewReader(data), int64(len(data)))
+}
+
+func (p *Processor) loadZip(zr *zip.Reader) error {
+	m := p.ensureFields()
+
+	manifest, err := readManifest(zr)
+	if err != nil {
+		return err
+	}
+
+	// The languages of the file are added to the configured ones, whose
+	// entries are required, so that their words are not silently routed to
+	// the default models, nor checked with the stale models.
+	configured := map[string]bool{}
+	languages := make(map[Language]LanguageModel, len(m.languages))
+	for language, lm := range m.languages {
+		configured[language.String()] = true
+		languages[language] = lm
+	}
+	for _, language := range manifest.languages() {
+		if _, ok := languages[language]; !ok {
+			languages[language] = LanguageModel{}
+		}
+	}
+	m.languages = withDefaultModels(languages)
+
+	components := m.components()
+	for i, c := range components {
+		if dir := path.Dir(c.name); configured[dir] {
+			components[i].optional = false
+		}
+	}
+	if err := manifest.validate(zr, components); err != nil {
+		return err
+	}
+
 	for _, f := range zr.File {
-		switch f.Name {
-		case scFileName:
-			if err := loadFromZipFile(p.SpellChecker, f); err != nil {
-				return err
+		for _, c := range components {
+			if f.Name != c.name {
+				continue
 			}
-		case smFileName:
-			if err := loadFromZipFile(p.SemanticMatcher, f); err != nil {
+			if err := loadFromZipFile(c.value, f); err != nil {
 				return err
 			}
 		}
 	}
 
+	p.mutex.Lock()
+	p.Languages = m.languages
+	p.training = manifest.Training
+	p.mutex.Unlock()
+
 	return nil
 }
 
@@ -240,54 +928,88 @@ func loadFromZipFile(v interface{}, f *zip.File) error {
 	return loader.Load(r)
 }
 
-// Save stores the state of the processor into w.
-func (p *Processor) Save(filePath string) (err error) {
-	p.ensureFields()
-
+// Save stores the state of the processor into filePath, along with a manifest
+// describing the saved components. The file is replaced atomically.
+func (p *Processor) Save(filePath string) error {
 	tempFile, err := ioutil.TempFile(path.Dir(filePath), "temp-*")
 	if err != nil {
 		return err
 	}
 
-	zw := zip.NewWriter(tempFile)
-
-	if err = saveToZipFile(p.SpellChecker, zw, scFileName); err != nil {
-		goto fail
+	if err := p.SaveTo(tempFile); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
 	}
 
-	if err = saveToZipFile(p.SemanticMatcher, zw, smFileName); err != nil {
-		goto fail
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
 	}
 
-	if err = zw.Close(); err != nil {
-		goto fail
-	}
+	return os.Rename(tempFile.Name(), filePath)
+}
 
-	tempFile.Close()
+// SaveTo stores the state of the processor into w, along with a manifest
+// describing the saved components.
+func (p *Processor) SaveTo(w io.Writer) error {
+	m := p.ensureFields()
+
+	zw := zip.NewWriter(w)
+	manifest := Manifest{
+		FormatVersion:  FormatVersion,
+		LibraryVersion: Version,
+		Components:     map[string]ManifestComponent{},
+		Training:       p.Training(),
+	}
 
-	return os.Rename(tempFile.Name(), filePath)
+	for _, c := range m.components() {
+		checksum, err := saveToZipFile(c.value, zw, c.name)
+		if err != nil {
+			return err
+		}
+		if checksum != "" {
+			manifest.Components[c.name] = ManifestComponent{
+				Type:   typeName(c.value),
+				SHA256: checksum,
+			}
+		}
+	}
 
-fail:
-	tempFile.Close()
-	os.Remove(tempFile.Name())
+	if err := saveManifest(&manifest, zw); err != nil {
+		return err
+	}
 
-	return err
+	return zw.Close()
 }
 
-func saveToZipFile(v interface{}, zw *zip.Writer, name string) error {
+func saveToZipFile(v interface{}, zw *zip.Writer, name string) (string, error) {
 	saver, ok := v.(LoadSaver)
 	if !ok {
-		return nil
+		return "", nil
 	}
 
 	w, err := zw.Create(name)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if err := saver.Save(w); err != nil {
+	cw := newChecksumWriter(w)
+	if err := saver.Save(cw); err != nil {
+		return "", err
+	}
+
+	return cw.Sum(), nil
+}
+
+func saveManifest(m *Manifest, zw *zip.Writer) error {
+	w, err := zw.Create(manifestFileName)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(m)
 }
@@ -1,10 +1,14 @@
 package ptpp_test
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"gopkg.in/ptpp.v1"
 
@@ -48,3 +52,144 @@ func TestProcessor(t *testing.T) {
 
 	NoError(t, processor.Load(filePath))
 }
+
+func TestProcessorMinConfidence(t *testing.T) {
+	processor := ptpp.Processor{MinConfidence: 0.6}
+	processor.Train([]string{"bass guitar", "bass guitar", "bass drum", "mass"})
+
+	phrases, err := processor.ProcessPhrases(strings.NewReader("bas guitarr"))
+	if !NoError(t, err) || !Len(t, phrases, 1) {
+		return
+	}
+
+	tokens := phrases[0].Tokens
+	if Len(t, tokens, 2) {
+		Equal(t, "bass", tokens[0].Text)
+		Equal(t, ptpp.Corrected, tokens[0].Status)
+		Equal(t, 0.75, tokens[0].Confidence)
+		Equal(t, "guitar", tokens[1].Text)
+		Equal(t, ptpp.Corrected, tokens[1].Status)
+	}
+
+	phrases, err = processor.ProcessPhrases(strings.NewReader("masss"))
+	if NoError(t, err) && Len(t, phrases, 1) {
+		Equal(t, []ptpp.Token{{Text: "masss", Status: ptpp.Unknown, Language: ptpp.EnglishLanguage}}, phrases[0].Tokens)
+	}
+}
+
+func TestProcessorSaveTo(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar"})
+
+	var buf bytes.Buffer
+	if !NoError(t, processor.SaveTo(&buf)) {
+		return
+	}
+
+	var loaded ptpp.Processor
+	if NoError(t, loaded.LoadFrom(bytes.NewReader(buf.Bytes()), int64(buf.Len()))) {
+		got, _ := loaded.Process(strings.NewReader("base guitarr"))
+		Equal(t, []string{"bass guitar"}, got)
+	}
+
+	fsys := fstest.MapFS{"models/model.zip": {Data: buf.Bytes()}}
+	var embedded ptpp.Processor
+	if NoError(t, embedded.LoadFS(fsys, "models/model.zip")) {
+		got, _ := embedded.Process(strings.NewReader("base guitarr"))
+		Equal(t, []string{"bass guitar"}, got)
+	}
+
+	Error(t, embedded.LoadFS(fsys, "models/missing.zip"))
+}
+
+func TestProcessorUntrain(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar", "bass gitar"})
+
+	if !NoError(t, processor.Untrain([]string{"bass gitar", "", "!!"})) {
+		return
+	}
+	Equal(t, int64(1), processor.Training().Phrases)
+
+	var buf bytes.Buffer
+	if !NoError(t, processor.SaveTo(&buf)) {
+		return
+	}
+
+	var loaded ptpp.Processor
+	loaded.Train([]string{"bass gitar"})
+	if NoError(t, loaded.LoadFrom(bytes.NewReader(buf.Bytes()), int64(buf.Len()))) {
+		got, _ := loaded.Process(strings.NewReader("bass gitar"))
+		Equal(t, []string{"bass guitar"}, got)
+	}
+
+	static := ptpp.Processor{SpellChecker: staticSpellChecker{}}
+	Error(t, static.Untrain([]string{"bass"}))
+}
+
+type staticSpellChecker struct{}
+
+func (staticSpellChecker) Check(word string) []string { return []string{word} }
+
+func (staticSpellChecker) Train(words []string) {}
+
+func TestProcessorContext(t *testing.T) {
+	processor := ptpp.Processor{SpellChecker: slowSpellChecker{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := processor.ProcessContext(ctx, strings.NewReader("bass guitar"))
+	Equal(t, context.DeadlineExceeded, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	Equal(t, context.Canceled, processor.TrainContext(ctx, []string{"bass guitar"}))
+	Equal(t, int64(0), processor.Training().Phrases)
+
+	processor = ptpp.Processor{SpellChecker: slowScoringSpellChecker{}}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = processor.ProcessContext(ctx, strings.NewReader("bass guitar"))
+	Equal(t, context.DeadlineExceeded, err)
+
+	processor = ptpp.Processor{SpellChecker: scoringContextSpellChecker{}, MinConfidence: 0.5}
+	got, err := processor.ProcessContext(context.Background(), strings.NewReader("bas"))
+	NoError(t, err)
+	Equal(t, []string{"bas"}, got)
+
+	processor = ptpp.Processor{}
+	NoError(t, processor.TrainContext(context.Background(), []string{"bass guitar"}))
+	got, err = processor.ProcessContext(context.Background(), strings.NewReader("base guitarr"))
+	NoError(t, err)
+	Equal(t, []string{"bass guitar"}, got)
+}
+
+// slowSpellChecker blocks until its context is done.
+type slowSpellChecker struct{ staticSpellChecker }
+
+func (slowSpellChecker) CheckContext(ctx context.Context, word string) ([]string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowSpellChecker) TrainContext(ctx context.Context, words []string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// scoringContextSpellChecker suggests "bass" with a low confidence.
+type scoringContextSpellChecker struct{ slowSpellChecker }
+
+func (scoringContextSpellChecker) CheckScored(word string) []ptpp.Suggestion {
+	return []ptpp.Suggestion{{Word: "bass", Confidence: 0.1}}
+}
+
+// slowScoringSpellChecker blocks until its context is done.
+type slowScoringSpellChecker struct{ scoringContextSpellChecker }
+
+func (slowScoringSpellChecker) CheckScoredContext(ctx context.Context, word string) ([]ptpp.Suggestion, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
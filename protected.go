@@ -0,0 +1,124 @@
+package ptpp
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WordSet is a set of normalized words.
+type WordSet struct {
+	words wordList
+	mutex sync.RWMutex
+}
+
+// Add adds the words of a phrase to the set.
+func (ws *WordSet) Add(phrase string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.add(phrase)
+}
+
+func (ws *WordSet) add(phrase string) {
+	if ws.words == nil {
+		ws.words = make(wordList)
+	}
+
+	words, _ := readWords(strings.NewReader(phrase))
+	for _, w := range words {
+		ws.words.Add(w)
+	}
+}
+
+// Has checks whether a normalized word is in the set.
+func (ws *WordSet) Has(word string) bool {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	return ws.words.Has(word)
+}
+
+// Len returns the number of words in the set.
+func (ws *WordSet) Len() int {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	return len(ws.words)
+}
+
+// Merge adds the words of another set to the set.
+func (ws *WordSet) Merge(other *WordSet) {
+	other.mutex.RLock()
+	words := other.words.clone()
+	other.mutex.RUnlock()
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	if ws.words == nil {
+		ws.words = make(wordList)
+	}
+	for word := range words {
+		ws.words[word] = 1
+	}
+}
+
+// Load replaces the words of the set with the words read from r. The input is
+// a plain text with one word per line. Empty lines and lines starting with '#'
+// are ignored. If reading fails, the set is not changed.
+func (ws *WordSet) Load(r io.Reader) error {
+	var loaded WordSet
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		loaded.add(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.words = loaded.words
+
+	return nil
+}
+
+// LoadFile replaces the words of the set with the words of a plain text file.
+func (ws *WordSet) LoadFile(filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return ws.Load(f)
+}
+
+// Save stores the words of the set into w, one word per line.
+func (ws *WordSet) Save(w io.Writer) error {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	words := make([]string, 0, len(ws.words))
+	for word := range ws.words {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	bw := bufio.NewWriter(w)
+	for _, word := range words {
+		bw.WriteString(word)
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,56 @@
+package ptpp_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProtectedWords(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar", "pepsi"})
+
+	processor.ProtectedWords = &ptpp.WordSet{}
+	NoError(t, processor.ProtectedWords.Load(strings.NewReader("# brands\nBase\n\npepsi\n")))
+	Equal(t, 2, processor.ProtectedWords.Len())
+
+	tests := []struct {
+		phrase string
+		want   []string
+	}{
+		{"electric base guitarr", []string{"electric", "base", "guitar"}},
+		{"pepsy", []string{"pepsy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			got, err := processor.Process(strings.NewReader(tt.phrase))
+			NoError(t, err)
+			Equal(t, tt.want, got)
+		})
+	}
+
+	workingDir, err := os.Getwd()
+	if !NoError(t, err) {
+		return
+	}
+
+	filePath := path.Join(workingDir, "test-protected.zip")
+	defer os.Remove(filePath)
+
+	if !NoError(t, processor.Save(filePath)) {
+		return
+	}
+
+	loaded := ptpp.Processor{ProtectedWords: &ptpp.WordSet{}}
+	loaded.ProtectedWords.Add("stale")
+	if NoError(t, loaded.Load(filePath)) {
+		True(t, loaded.ProtectedWords.Has("base"))
+		True(t, loaded.ProtectedWords.Has("pepsi"))
+		False(t, loaded.ProtectedWords.Has("stale"))
+	}
+}
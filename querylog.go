@@ -0,0 +1,246 @@
+package ptpp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// QueryLogEntry is an entry of a search query log.
+type QueryLogEntry struct {
+
+	// Query is the query as entered by the user.
+	Query string
+
+	// Rewrite is the query which the user rewrote Query into, if any.
+	Rewrite string
+
+	// Clicked denotes that the user clicked a result of the final query.
+	Clicked bool
+}
+
+// CorrectionLearner is a SpellChecker which can store explicit corrections,
+// such as DefaultSpellChecker.
+type CorrectionLearner interface {
+
+	// AddCorrection adds an explicit correction of a misspelling.
+	AddCorrection(misspelling, correction string)
+}
+
+// defaultMinCorrectionCount is the default minimum number of clicked rewrites
+// for learning a correction, so that a single stray rewrite does not override
+// a correct word.
+const defaultMinCorrectionCount = 3
+
+// QueryLogOptions are the options of training with a query log.
+type QueryLogOptions struct {
+
+	// MinCount is the minimum number of clicked rewrites of a misspelling
+	// into the same correction for learning the correction. If this field is
+	// not positive, three rewrites are required.
+	MinCount int
+}
+
+// TrainQueryLog trains the preprocessing model with a query log read from r.
+// Each line of the log has three tab-separated fields: the query, the rewritten
+// query, which may be empty, and whether a result was clicked, e.g. "true".
+// See TrainQueryLogEntries for how the log is used. If the log cannot be read
+// or parsed, the model is not changed.
+//
+// The log is trained into a separate model, which is merged into the model at
+// the end, and only the counts of the corrections are kept in memory. This
+// requires DefaultSpellChecker and DefaultSemanticMatcher, also for the
+// language models. Other components are trained as the log is read, so the
+// queries read before an error stay trained.
+func (p *Processor) TrainQueryLog(r io.Reader, opts QueryLogOptions) error {
+	t, err := p.newQueryLogTrainer()
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			return fmt.Errorf("ptpp: query log line %d has %d fields instead of 3", line, len(fields))
+		}
+		clicked, err := strconv.ParseBool(strings.TrimSpace(fields[2]))
+		if err != nil {
+			return fmt.Errorf("ptpp: query log line %d: %v", line, err)
+		}
+
+		t.add(QueryLogEntry{Query: fields[0], Rewrite: fields[1], Clicked: clicked})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return t.finish(opts)
+}
+
+// TrainQueryLogEntries trains the preprocessing model with the entries of a
+// query log. The clicked queries are trained as correct phrases. The words of
+// a clicked rewrite which replace similar words of the original query are
+// learnt as explicit corrections of them, which requires the spell-checker to
+// implement CorrectionLearner. The entries without a click are ignored.
+func (p *Processor) TrainQueryLogEntries(entries []QueryLogEntry, opts QueryLogOptions) error {
+	t, err := p.newQueryLogTrainer()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		t.add(entry)
+	}
+
+	return t.finish(opts)
+}
+
+// queryLogBatchSize is the number of queries which are trained at once.
+const queryLogBatchSize = 1024
+
+// queryLogTrainer trains the clicked queries of a log in batches, into a shard
+// of the processor if possible, and counts their corrections.
+type queryLogTrainer struct {
+	p      *Processor
+	m      models
+	target *Processor
+	pairs  map[correctionPair]int
+	batch  []string
+}
+
+func (p *Processor) newQueryLogTrainer() (*queryLogTrainer, error) {
+	m := p.ensureFields()
+	for _, lm := range m.all() {
+		if _, ok := lm.spellChecker.(CorrectionLearner); !ok {
+			return nil, fmt.Errorf("ptpp: cannot learn corrections with spell-checker of type %T", lm.spellChecker)
+		}
+	}
+
+	target := p
+	if isMergeable(m) {
+		target = p.newShard(m)
+	}
+
+	return &queryLogTrainer{
+		p:      p,
+		m:      m,
+		target: target,
+		pairs:  map[correctionPair]int{},
+		batch:  make([]string, 0, queryLogBatchSize),
+	}, nil
+}
+
+func (t *queryLogTrainer) add(entry QueryLogEntry) {
+	if !entry.Clicked {
+		return
+	}
+
+	phrase := entry.Query
+	if strings.TrimSpace(entry.Rewrite) != "" {
+		phrase = entry.Rewrite
+		for _, pair := range t.p.alignCorrections(t.m, entry.Query, entry.Rewrite) {
+			t.pairs[pair]++
+		}
+	}
+
+	t.batch = append(t.batch, phrase)
+	if len(t.batch) == queryLogBatchSize {
+		t.target.Train(t.batch)
+		t.batch = t.batch[:0]
+	}
+}
+
+// finish trains the last batch, merges the shard into the processor and learns
+// the corrections seen at least opts.MinCount times.
+func (t *queryLogTrainer) finish(opts QueryLogOptions) error {
+	if len(t.batch) > 0 {
+		t.target.Train(t.batch)
+	}
+	if t.target != t.p {
+		if err := t.p.Merge(t.target); err != nil {
+			return err
+		}
+	}
+
+	minCount := opts.MinCount
+	if minCount <= 0 {
+		minCount = defaultMinCorrectionCount
+	}
+
+	best := map[string]correctionPair{}
+	for pair, count := range t.pairs {
+		if count < minCount {
+			continue
+		}
+		b, ok := best[pair.misspelling]
+		if !ok || count > t.pairs[b] || (count == t.pairs[b] && pair.correction < b.correction) {
+			best[pair.misspelling] = pair
+		}
+	}
+
+	for _, pair := range best {
+		learner := t.m.language(pair.language).spellChecker.(CorrectionLearner)
+		learner.AddCorrection(pair.misspelling, pair.correction)
+	}
+
+	return nil
+}
+
+type correctionPair struct {
+	misspelling, correction string
+	language                Language
+}
+
+// alignCorrections finds the words of a query which are replaced by similar
+// words in its rewrite. The query and its rewrite must have the same number of
+// words.
+func (p *Processor) alignCorrections(m models, query, rewrite string) []correctionPair {
+	queryWords := wordTokens(p.tokenize(m, query))
+	rewriteWords := wordTokens(p.tokenize(m, rewrite))
+	if len(queryWords) != len(rewriteWords) {
+		return nil
+	}
+
+	pairs := []correctionPair{}
+	for i, tok := range queryWords {
+		word, correction := tok.Text, rewriteWords[i].Text
+		if word == correction {
+			continue
+		}
+
+		maxDistance := utf8.RuneCountInString(correction) / 3
+		if maxDistance < 1 {
+			maxDistance = 1
+		}
+		if Levenshtein(word, correction) > maxDistance {
+			return nil
+		}
+
+		pairs = append(pairs, correctionPair{misspelling: word, correction: correction, language: tok.Language})
+	}
+
+	return pairs
+}
+
+// wordTokens returns the word tokens.
+func wordTokens(tokens []Token) []Token {
+	words := []Token{}
+	for _, tok := range tokens {
+		if tok.Type == WordToken {
+			words = append(words, tok)
+		}
+	}
+	return words
+}
@@ -0,0 +1,58 @@
+package ptpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorTrainQueryLog(t *testing.T) {
+	log := strings.Join([]string{
+		"bas guitar\tbass guitar\ttrue",
+		"bas guitar\tbass guitar\t1",
+		"bas drum\tbase drum\ttrue",
+		"spanish rosetta stone\t\ttrue",
+		"cheap flights\thotels in paris\ttrue",
+		"guitarr\tguitars\tfalse",
+	}, "\n")
+
+	tests := []struct {
+		minCount int
+		want     map[string]string
+	}{
+		{2, map[string]string{"bas": "bass"}},
+		{0, map[string]string{}},
+		{3, map[string]string{}},
+	}
+	for _, tt := range tests {
+		var processor ptpp.Processor
+		if !NoError(t, processor.TrainQueryLog(strings.NewReader(log), ptpp.QueryLogOptions{MinCount: tt.minCount})) {
+			continue
+		}
+
+		Equal(t, int64(5), processor.Training().Phrases)
+		Equal(t, tt.want, processor.SpellChecker.(*ptpp.DefaultSpellChecker).Corrections())
+	}
+
+	var processor ptpp.Processor
+	NoError(t, processor.TrainQueryLogEntries([]ptpp.QueryLogEntry{
+		{Query: "bas guitar", Rewrite: "bass guitar", Clicked: true},
+		{Query: "spanish rosetta stone", Clicked: true},
+	}, ptpp.QueryLogOptions{MinCount: 1}))
+
+	got, _ := processor.Process(strings.NewReader("bas guitar"))
+	Equal(t, []string{"bass guitar"}, got)
+
+	Error(t, processor.TrainQueryLog(strings.NewReader("bas guitar\ttrue"), ptpp.QueryLogOptions{}))
+	Error(t, processor.TrainQueryLog(strings.NewReader("bas\tbass\tmaybe"), ptpp.QueryLogOptions{}))
+
+	var partial ptpp.Processor
+	Error(t, partial.TrainQueryLog(strings.NewReader("bas guitar\tbass guitar\ttrue\nbroken"), ptpp.QueryLogOptions{MinCount: 1}))
+	Equal(t, int64(0), partial.Training().Phrases)
+
+	custom := ptpp.Processor{SpellChecker: staticSpellChecker{}}
+	Error(t, custom.TrainQueryLog(strings.NewReader(log), ptpp.QueryLogOptions{}))
+}
@@ -0,0 +1,92 @@
+package ptpp
+
+import (
+	"regexp"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// recognizer detects a pattern in the raw input which must be kept as a single
+// token instead of being split into words.
+type recognizer struct {
+	tokenType TokenType
+	pattern   *regexp.Regexp
+
+	// canonicalize converts the submatches of pattern into the text of the
+	// token. If the match is not valid, it returns false.
+	canonicalize func(submatches []string) (string, bool)
+}
+
+type span struct {
+	start, end int
+	token      Token
+}
+
+// recognize finds the non-overlapping spans of text matched by the
+// recognizers. Overlaps are resolved in favour of the earliest and then the
+// longest span.
+func recognize(text string, recognizers []recognizer) []span {
+	spans := []span{}
+
+	for _, rec := range recognizers {
+		for _, loc := range rec.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if !isBoundary(text, start, end) {
+				continue
+			}
+
+			submatches := make([]string, len(loc)/2)
+			for i := range submatches {
+				if loc[2*i] >= 0 {
+					submatches[i] = text[loc[2*i]:loc[2*i+1]]
+				}
+			}
+
+			canonical, ok := rec.canonicalize(submatches)
+			if !ok {
+				continue
+			}
+
+			spans = append(spans, span{
+				start: start,
+				end:   end,
+				token: Token{Text: canonical, Type: rec.tokenType},
+			})
+		}
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	result := spans[:0]
+	end := 0
+	for _, s := range spans {
+		if s.start >= end {
+			result = append(result, s)
+			end = s.end
+		}
+	}
+
+	return result
+}
+
+// isBoundary checks that text[start:end] is not a part of a longer word or
+// number.
+func isBoundary(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
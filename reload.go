@@ -0,0 +1,135 @@
+package ptpp
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Swap atomically replaces the model of the processor, i.e. its spell-checker,
+// semantic matcher, language models, protected words, rules and training
+// metadata, with the model of another processor. Operations in progress
+// finish with the previous model.
+//
+// After swapping, the two processors share the same components.
+func (p *Processor) Swap(other *Processor) {
+	m := other.ensureFields()
+	training := other.Training()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.SpellChecker = m.spellChecker
+	p.SemanticMatcher = m.semanticMatcher
+	p.Languages = m.languages
+	p.ProtectedWords = m.protectedWords
+	p.Rules = m.rules
+	p.training = training
+}
+
+// Reload loads a saved model into new components of the same types as the
+// current ones and swaps them into the processor. Unlike Load, it is safe to
+// call Reload while the processor is in use. If loading fails, the current
+// model is kept.
+func (p *Processor) Reload(filePath string) error {
+	next := p.newEmpty()
+	if err := next.Load(filePath); err != nil {
+		return err
+	}
+
+	p.Swap(next)
+
+	return nil
+}
+
+// newEmpty creates a processor with the options of the processor and empty
+// components of the same types, which a model can be loaded into. The
+// components which are not LoadSavers are shared, since they are not stored in
+// the model files.
+func (p *Processor) newEmpty() *Processor {
+	m := p.ensureFields()
+
+	languages := make(map[Language]LanguageModel, len(m.languages))
+	for language, lm := range m.languages {
+		languages[language] = LanguageModel{
+			SpellChecker:    emptyComponent(lm.SpellChecker).(SpellChecker),
+			SemanticMatcher: emptyComponent(lm.SemanticMatcher).(SemanticMatcher),
+		}
+	}
+
+	return &Processor{
+		SpellChecker:      emptyComponent(m.spellChecker).(SpellChecker),
+		SemanticMatcher:   emptyComponent(m.semanticMatcher).(SemanticMatcher),
+		Languages:         languages,
+		MinConfidence:     p.MinConfidence,
+		ConvertNumbers:    p.ConvertNumbers,
+		RecognizeDates:    p.RecognizeDates,
+		DateCalendar:      p.DateCalendar,
+		RecognizeEntities: p.RecognizeEntities,
+		Expander:          p.Expander,
+		SplitLanguages:    p.SplitLanguages,
+	}
+}
+
+// emptyComponent creates an empty component of the same type and options as
+// a component. A component which is not a LoadSaver is returned as is.
+func emptyComponent(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *DefaultSpellChecker:
+		return &DefaultSpellChecker{Phonetic: v.Phonetic}
+	case *DefaultSemanticMatcher:
+		return &DefaultSemanticMatcher{}
+	}
+
+	t := reflect.TypeOf(v)
+	if !isLoadSaver(v) || t.Kind() != reflect.Ptr {
+		return v
+	}
+	return reflect.New(t.Elem()).Interface()
+}
+
+// Watch checks filePath every interval and reloads the model whenever the file
+// is modified, e.g. by Save in another process. The result of each reload is
+// passed to onReload, which may be nil. The returned function stops watching.
+func (p *Processor) Watch(filePath string, interval time.Duration, onReload func(error)) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	last, _ := os.Stat(filePath)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(filePath)
+			if err != nil || (last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+				continue
+			}
+			last = info
+
+			err = p.Reload(filePath)
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
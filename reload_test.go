@@ -0,0 +1,119 @@
+package ptpp_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorReload(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if !NoError(t, err) {
+		return
+	}
+
+	filePath := path.Join(workingDir, "test-reload.zip")
+	defer os.Remove(filePath)
+
+	var next ptpp.Processor
+	next.Train([]string{"garbage collector"})
+	if !NoError(t, next.Save(filePath)) {
+		return
+	}
+
+	var processor ptpp.Processor
+	processor.Train([]string{"bass guitar"})
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				got, err := processor.Process(strings.NewReader("base guitarr"))
+				NoError(t, err)
+				Len(t, got, 1)
+			}
+		}()
+	}
+
+	Error(t, processor.Reload(path.Join(workingDir, "missing.zip")))
+	NoError(t, processor.Reload(filePath))
+	close(done)
+	wg.Wait()
+
+	got, _ := processor.Process(strings.NewReader("garbage colector"))
+	Equal(t, []string{"garbage collector"}, got)
+
+	reloaded := make(chan error, 1)
+	stop := processor.Watch(filePath, 10*time.Millisecond, func(err error) {
+		reloaded <- err
+	})
+	defer stop()
+
+	next.Train([]string{"spanish rosetta stone"})
+	time.Sleep(20 * time.Millisecond)
+	if !NoError(t, next.Save(filePath)) {
+		return
+	}
+
+	select {
+	case err := <-reloaded:
+		NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("model was not reloaded")
+	}
+
+	got, _ = processor.Process(strings.NewReader("spannish rosetta stone"))
+	Equal(t, []string{"spanish rosetta stone"}, got)
+}
+
+func TestProcessorReloadKeepsTypes(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if !NoError(t, err) {
+		return
+	}
+
+	filePath := path.Join(workingDir, "test-reload-types.zip")
+	defer os.Remove(filePath)
+
+	saved := ptpp.Processor{SpellChecker: &customSpellChecker{}}
+	saved.Train([]string{"garbage collector"})
+	if !NoError(t, saved.Save(filePath)) {
+		return
+	}
+
+	processor := ptpp.Processor{SpellChecker: &customSpellChecker{}}
+	if !NoError(t, processor.Reload(filePath)) {
+		return
+	}
+
+	sc, ok := processor.SpellChecker.(*customSpellChecker)
+	if True(t, ok) {
+		Contains(t, sc.Check("garbag"), "garbage")
+	}
+
+	phonetic := ptpp.Processor{SpellChecker: &ptpp.DefaultSpellChecker{Phonetic: true}}
+	var plain ptpp.Processor
+	plain.Train([]string{"phone"})
+	if !NoError(t, plain.Save(filePath)) || !NoError(t, phonetic.Reload(filePath)) {
+		return
+	}
+	if sc, ok := phonetic.SpellChecker.(*ptpp.DefaultSpellChecker); True(t, ok) {
+		True(t, sc.Phonetic)
+		Contains(t, sc.Check("fone"), "phone")
+	}
+}
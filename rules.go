@@ -0,0 +1,209 @@
+package ptpp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ruleSeparator separates the source and the target of a rule in a rule file.
+const ruleSeparator = "=>"
+
+// RuleSet is a table of rules which map a source phrase into a target phrase,
+// such as "pepsi cola => pepsi" or "آی فون => آیفون". The rules are applied to
+// the input before spell-checking, and their targets are never corrected.
+type RuleSet struct {
+	rules     map[string][]string
+	maxSource int
+	mutex     sync.RWMutex
+}
+
+// Add adds a rule which maps the source phrase into the target phrase. The
+// phrases are normalized like the input. An empty target removes the source
+// phrase from the input.
+func (rs *RuleSet) Add(source, target string) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	rs.add(source, target)
+}
+
+func (rs *RuleSet) add(source, target string) {
+	sourceWords, _ := readWords(strings.NewReader(source))
+	if len(sourceWords) == 0 {
+		return
+	}
+	targetWords, _ := readWords(strings.NewReader(target))
+
+	rs.set(sourceWords, targetWords)
+}
+
+func (rs *RuleSet) set(sourceWords, targetWords []string) {
+	if rs.rules == nil {
+		rs.rules = make(map[string][]string)
+	}
+
+	rs.rules[strings.Join(sourceWords, " ")] = targetWords
+	if len(sourceWords) > rs.maxSource {
+		rs.maxSource = len(sourceWords)
+	}
+}
+
+// Len returns the number of rules in the set.
+func (rs *RuleSet) Len() int {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+
+	return len(rs.rules)
+}
+
+// Merge adds the rules of another set to the set. The rules of the other set
+// replace the existing rules with the same sources.
+func (rs *RuleSet) Merge(other *RuleSet) {
+	other.mutex.RLock()
+	rules := make(map[string][]string, len(other.rules))
+	for source, target := range other.rules {
+		rules[source] = target
+	}
+	other.mutex.RUnlock()
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	for source, target := range rules {
+		rs.set(strings.Split(source, " "), target)
+	}
+}
+
+// Load replaces the rules of the set with the rules read from r. The input is
+// a plain text with one rule per line, in the form of "source => target".
+// Empty lines and lines starting with '#' are ignored. If reading fails, the
+// set is not changed.
+func (rs *RuleSet) Load(r io.Reader) error {
+	var loaded RuleSet
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		i := strings.Index(text, ruleSeparator)
+		if i < 0 {
+			return fmt.Errorf("ptpp: rule on line %d has no %q", line, ruleSeparator)
+		}
+		loaded.add(text[:i], text[i+len(ruleSeparator):])
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	rs.rules = loaded.rules
+	rs.maxSource = loaded.maxSource
+
+	return nil
+}
+
+// LoadFile replaces the rules of the set with the rules of a plain text file.
+func (rs *RuleSet) LoadFile(filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return rs.Load(f)
+}
+
+// Save stores the rules of the set into w, one rule per line.
+func (rs *RuleSet) Save(w io.Writer) error {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+
+	sources := make([]string, 0, len(rs.rules))
+	for source := range rs.rules {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	bw := bufio.NewWriter(w)
+	for _, source := range sources {
+		bw.WriteString(source)
+		bw.WriteString(" " + ruleSeparator + " ")
+		bw.WriteString(strings.Join(rs.rules[source], " "))
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// apply replaces the runs of words matched by the rules with their targets.
+// The longest matching source wins, and the target words span the source.
+func (rs *RuleSet) apply(spans []span) []span {
+	rs.mutex.RLock()
+	defer rs.mutex.RUnlock()
+
+	if len(rs.rules) == 0 {
+		return spans
+	}
+
+	result := make([]span, 0, len(spans))
+	for i := 0; i < len(spans); {
+		n, target := rs.match(spans[i:])
+		if n == 0 {
+			result = append(result, spans[i])
+			i++
+			continue
+		}
+
+		source := make([]string, n)
+		for j := range source {
+			source[j] = spans[i+j].token.Text
+		}
+
+		status := Corrected
+		if strings.Join(source, " ") == strings.Join(target, " ") {
+			status = Unchanged
+		}
+		for _, word := range target {
+			result = append(result, span{
+				start: spans[i].start,
+				end:   spans[i+n-1].end,
+				token: Token{Text: word, Type: WordToken, Status: status, Language: wordLanguage(word), Confidence: 1, fixed: true},
+			})
+		}
+
+		i += n
+	}
+
+	return result
+}
+
+// match finds the longest source matching the beginning of spans, and returns
+// the number of matched spans along with the target.
+func (rs *RuleSet) match(spans []span) (int, []string) {
+	words := []string{}
+	for _, s := range spans {
+		if s.token.Type != WordToken || len(words) == rs.maxSource {
+			break
+		}
+		words = append(words, s.token.Text)
+	}
+
+	for n := len(words); n > 0; n-- {
+		if target, ok := rs.rules[strings.Join(words[:n], " ")]; ok {
+			return n, target
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,59 @@
+package ptpp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	var processor ptpp.Processor
+	processor.Train([]string{"pepsy cola", "bass guitar", "آیفون"})
+
+	processor.Rules = &ptpp.RuleSet{}
+	NoError(t, processor.Rules.Load(strings.NewReader("# brands\nPepsi Cola => pepsi\n\nآی فون => آیفون\nmusic =>\n")))
+	Equal(t, 3, processor.Rules.Len())
+	Error(t, processor.Rules.Load(strings.NewReader("pepsi cola")))
+	Equal(t, 3, processor.Rules.Len())
+
+	tests := []struct {
+		phrase string
+		want   []string
+	}{
+		{"pepsi cola", []string{"pepsi"}},
+		{"pepsi", []string{"pepsy"}},
+		{"آی فون", []string{"ایفون"}},
+		{"music base guitarr", []string{"bass guitar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			got, err := processor.Process(strings.NewReader(tt.phrase))
+			NoError(t, err)
+			Equal(t, tt.want, got)
+		})
+	}
+
+	phrases, err := processor.ProcessPhrases(strings.NewReader("pepsi cola"))
+	if NoError(t, err) {
+		Equal(t, []ptpp.Phrase{{Tokens: []ptpp.Token{{Text: "pepsi", Status: ptpp.Corrected, Language: ptpp.EnglishLanguage, Confidence: 1}}}}, phrases)
+	}
+
+	var buf bytes.Buffer
+	if !NoError(t, processor.SaveTo(&buf)) {
+		return
+	}
+
+	loaded := ptpp.Processor{Rules: &ptpp.RuleSet{}}
+	loaded.Rules.Add("a b c d", "stale")
+	if NoError(t, loaded.LoadFrom(bytes.NewReader(buf.Bytes()), int64(buf.Len()))) {
+		Equal(t, 3, loaded.Rules.Len())
+		got, _ := loaded.Process(strings.NewReader("a b c d"))
+		NotContains(t, got, "stale")
+		got, _ = loaded.Process(strings.NewReader("pepsi cola"))
+		Equal(t, []string{"pepsi"}, got)
+	}
+}
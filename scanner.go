@@ -0,0 +1,134 @@
+package ptpp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"unicode/utf8"
+)
+
+// scanChunkSize is the maximum size of the input which is tokenized at once.
+const scanChunkSize = 64 * 1024
+
+// PhraseScanner reads an input incrementally and extracts its phrases one by
+// one, like Process. It uses a bounded amount of memory regardless of the size
+// of the input, so it is suitable for large inputs such as document dumps.
+//
+// Unlike Process, the scanner tokenizes the input line by line, and splits the
+// lines longer than 64KB at white spaces. A date, entity, spelled-out number or
+// multi-word rule crossing a line break or such a split is not recognized, e.g.
+// with ConvertNumbers, "thirty\nfive" is 35 for Process but 30 and 5 for the
+// scanner.
+type PhraseScanner struct {
+	ctx     context.Context
+	br      *bufio.Reader
+	carry   []byte
+	builder phraseBuilder
+	pending []Phrase
+	phrase  Phrase
+	done    bool
+	err     error
+}
+
+// NewScanner returns a PhraseScanner reading from r.
+func (p *Processor) NewScanner(r io.Reader) *PhraseScanner {
+	return p.NewScannerContext(context.Background(), r)
+}
+
+// NewScannerContext is like NewScanner, but the scanner stops when ctx is done
+// and reports ctx.Err().
+func (p *Processor) NewScannerContext(ctx context.Context, r io.Reader) *PhraseScanner {
+	return &PhraseScanner{
+		ctx:     ctx,
+		br:      bufio.NewReaderSize(r, scanChunkSize),
+		builder: phraseBuilder{p: p, m: p.ensureFields(), expander: p.Expander},
+	}
+}
+
+// Scan advances the scanner to the next phrase, which will then be available
+// through the Phrase method. It returns false when the scan stops, either by
+// reaching the end of the input or an error.
+func (s *PhraseScanner) Scan() bool {
+	emit := func(phrase Phrase) {
+		s.pending = append(s.pending, phrase)
+	}
+
+	for len(s.pending) == 0 {
+		if s.done || s.err != nil {
+			return false
+		}
+
+		chunk, err := s.readChunk()
+		if err != nil && err != io.EOF {
+			s.err = err
+			return false
+		}
+
+		for _, tok := range s.builder.p.tokenize(s.builder.m, chunk) {
+			if err := s.builder.add(s.ctx, tok, emit); err != nil {
+				s.err = err
+				return false
+			}
+		}
+
+		if err == io.EOF {
+			s.builder.flush(emit)
+			s.done = true
+		}
+	}
+
+	s.phrase = s.pending[0]
+	s.pending = s.pending[1:]
+
+	return true
+}
+
+// Phrase returns the most recent phrase extracted by Scan.
+func (s *PhraseScanner) Phrase() Phrase {
+	return s.phrase
+}
+
+// Text returns the text of the most recent phrase extracted by Scan.
+func (s *PhraseScanner) Text() string {
+	return s.phrase.String()
+}
+
+// Err returns the first error encountered by the scanner.
+func (s *PhraseScanner) Err() error {
+	return s.err
+}
+
+// readChunk reads the next line of the input, or a part of it which ends with
+// a white space if the line is too long.
+func (s *PhraseScanner) readChunk() (string, error) {
+	line, err := s.br.ReadSlice('\n')
+
+	chunk := append(s.carry, line...)
+	s.carry = nil
+
+	if err == bufio.ErrBufferFull {
+		cut := chunkEnd(chunk)
+		s.carry = append([]byte(nil), chunk[cut:]...)
+		chunk = chunk[:cut]
+		err = nil
+	}
+
+	return string(chunk), err
+}
+
+// chunkEnd finds the end of the chunk after its last white space. If there is
+// no white space, the chunk ends at its last complete rune.
+func chunkEnd(chunk []byte) int {
+	if i := bytes.LastIndexAny(chunk, " \t\r\v\f"); i >= 0 {
+		return i + 1
+	}
+
+	for i := len(chunk) - 1; i > 0 && i >= len(chunk)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(chunk[i]) {
+			return i
+		}
+	}
+
+	return len(chunk)
+}
@@ -0,0 +1,72 @@
+package ptpp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestPhraseScanner(t *testing.T) {
+	processor := ptpp.Processor{RecognizeEntities: true}
+	processor.Train([]string{
+		"bass guitar",
+		"spanish rosetta stone",
+	})
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"lines", "electric base guitarr\nspannish rosetta stone info@example.com\n\nenglish rosetta stone"},
+		{"long line", strings.Repeat("electric base guitarr ", 10000)},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := processor.Process(strings.NewReader(tt.input))
+			if !NoError(t, err) {
+				return
+			}
+
+			got := []string{}
+			scanner := processor.NewScanner(strings.NewReader(tt.input))
+			for scanner.Scan() {
+				got = append(got, scanner.Text())
+			}
+
+			NoError(t, scanner.Err())
+			Equal(t, want, got)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := processor.NewScannerContext(ctx, strings.NewReader("bass guitar"))
+	False(t, scanner.Scan())
+	Equal(t, context.Canceled, scanner.Err())
+}
+
+func TestPhraseScannerLineBreaks(t *testing.T) {
+	processor := ptpp.Processor{ConvertNumbers: true}
+	processor.Train([]string{"bass"})
+
+	const input = "thirty\nfive bass"
+
+	got, err := processor.Process(strings.NewReader(input))
+	if NoError(t, err) {
+		Equal(t, []string{"35", "bass"}, got)
+	}
+
+	scanned := []string{}
+	scanner := processor.NewScanner(strings.NewReader(input))
+	for scanner.Scan() {
+		scanned = append(scanned, scanner.Text())
+	}
+	NoError(t, scanner.Err())
+	Equal(t, []string{"30", "5", "bass"}, scanned)
+}
@@ -49,8 +49,58 @@ func (sm *DefaultSemanticMatcher) Train(context, word string) {
 	sm.contexts[context].Add(word)
 }
 
-// Load restores the state of the semantic-matcher from r.
-func (sm *DefaultSemanticMatcher) Load(r io.Reader) error {
+// Untrain removes the contribution of a word and its context from the
+// semantic model, i.e. reverts training with them.
+func (sm *DefaultSemanticMatcher) Untrain(context, word string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if list, ok := sm.contexts[context]; ok {
+		list.remove(word)
+		if len(list) == 0 {
+			delete(sm.contexts, context)
+		}
+	}
+}
+
+// Remove removes a word from a context, regardless of how many times it was
+// trained.
+func (sm *DefaultSemanticMatcher) Remove(context, word string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if list, ok := sm.contexts[context]; ok {
+		delete(list, word)
+		if len(list) == 0 {
+			delete(sm.contexts, context)
+		}
+	}
+}
+
+// Prune removes the words trained less than minCount times with a context
+// from the semantic model.
+func (sm *DefaultSemanticMatcher) Prune(minCount int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for context, list := range sm.contexts {
+		list.prune(minCount)
+		if len(list) == 0 {
+			delete(sm.contexts, context)
+		}
+	}
+}
+
+// Merge adds the contexts of another semantic matcher to the semantic
+// matcher, summing the frequencies.
+func (sm *DefaultSemanticMatcher) Merge(other *DefaultSemanticMatcher) {
+	other.mutex.RLock()
+	contexts := make(map[string]wordList, len(other.contexts))
+	for context, list := range other.contexts {
+		contexts[context] = list.clone()
+	}
+	other.mutex.RUnlock()
+
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -58,7 +108,35 @@ func (sm *DefaultSemanticMatcher) Load(r io.Reader) error {
 		sm.contexts = make(map[string]wordList)
 	}
 
-	return gob.NewDecoder(r).Decode(&sm.contexts)
+	for context, list := range contexts {
+		if _, ok := sm.contexts[context]; !ok {
+			sm.contexts[context] = make(wordList)
+		}
+		sm.contexts[context].merge(list)
+	}
+}
+
+// Load restores the state of the semantic-matcher from r, replacing its
+// current state.
+func (sm *DefaultSemanticMatcher) Load(r io.Reader) error {
+	contexts := make(map[string]wordList)
+	legacy := map[string]legacyWordList{}
+	isLegacy, err := decodeGob(r, &contexts, &legacy)
+	if err != nil {
+		return err
+	}
+	if isLegacy {
+		for context, list := range legacy {
+			contexts[context] = list.toWordList()
+		}
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.contexts = contexts
+
+	return nil
 }
 
 // Save stores the state of the semantic-matcher into w.
@@ -29,3 +29,22 @@ func TestDefaultSemanticMatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultSemanticMatcherUntrain(t *testing.T) {
+	var semanticMatcher ptpp.DefaultSemanticMatcher
+	semanticMatcher.Train("best", "quality")
+	semanticMatcher.Train("best", "quality")
+	semanticMatcher.Train("best", "quantity")
+
+	semanticMatcher.Remove("best", "quantity")
+	_, matched := semanticMatcher.Match("best", []string{"quantity"})
+	False(t, matched)
+
+	semanticMatcher.Untrain("best", "quality")
+	_, matched = semanticMatcher.Match("best", []string{"quality"})
+	True(t, matched)
+
+	semanticMatcher.Untrain("best", "quality")
+	_, matched = semanticMatcher.Match("best", []string{"quality"})
+	False(t, matched)
+}
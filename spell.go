@@ -1,16 +1,19 @@
 package ptpp
 
 import (
+	"bytes"
 	"encoding/gob"
 	"io"
+	"io/ioutil"
+	"sort"
 	"sync"
 	"unicode/utf8"
 )
 
-type wordList map[string]bool
+type wordList map[string]int
 
 func (w wordList) Add(word string) {
-	w[word] = true
+	w[word]++
 }
 
 func (w wordList) Has(word string) bool {
@@ -18,57 +21,266 @@ func (w wordList) Has(word string) bool {
 	return ok
 }
 
+func (w wordList) Count(word string) int {
+	return w[word]
+}
+
+// remove decreases the frequency of a word and deletes it when it reaches
+// zero.
+func (w wordList) remove(word string) {
+	if count, ok := w[word]; ok {
+		if count <= 1 {
+			delete(w, word)
+		} else {
+			w[word] = count - 1
+		}
+	}
+}
+
+// prune removes the words with a count less than minCount.
+func (w wordList) prune(minCount int) {
+	for word, count := range w {
+		if count < minCount {
+			delete(w, word)
+		}
+	}
+}
+
+func (w wordList) merge(other wordList) {
+	for word, count := range other {
+		w[word] += count
+	}
+}
+
+func (w wordList) clone() wordList {
+	list := make(wordList, len(w))
+	list.merge(w)
+	return list
+}
+
+// legacyWordList is the format of word lists in the models saved before the
+// word frequencies were stored.
+type legacyWordList map[string]bool
+
+func (w legacyWordList) toWordList() wordList {
+	list := make(wordList, len(w))
+	for word := range w {
+		list[word] = 1
+	}
+	return list
+}
+
+// decodeGob decodes a gob value from r into v, followed by the optional
+// values, which are absent in the models saved before their introduction. If
+// the value is not in the format of v, it is decoded into legacy instead.
+func decodeGob(r io.Reader, v, legacy interface{}, optional ...interface{}) (isLegacy bool, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	err = dec.Decode(v)
+	if err == nil {
+		for _, o := range optional {
+			if err := dec.Decode(o); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	if gob.NewDecoder(bytes.NewReader(data)).Decode(legacy) != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Suggestion is a spell suggestion for a word.
+type Suggestion struct {
+
+	// Word is the suggested word.
+	Word string
+
+	// Confidence is the estimated probability of Word being the intended
+	// word, in the range of 0 to 1.
+	Confidence float64
+}
+
+// ScoringSpellChecker is a SpellChecker which can estimate the confidence of
+// its suggestions.
+type ScoringSpellChecker interface {
+	SpellChecker
+
+	// CheckScored finds correct spell suggestions for a word along with their
+	// confidences. The resulted suggestion list should not be empty. An
+	// unknown word without any suggestions is returned with zero confidence.
+	CheckScored(word string) []Suggestion
+}
+
 // DefaultSpellChecker is a SpellChecker which uses a distance model to find
 // suggestions for a misspelled word.
 type DefaultSpellChecker struct {
-	lexicon map[int]wordList
-	mutex   sync.RWMutex
+
+	// Phonetic enables finding suggestions which sound like a misspelled
+	// word, i.e. which have the same PhoneticKey, in addition to the ones
+	// within one edit of it. Compiled models do not support it.
+	Phonetic bool
+
+	lexicon     map[int]wordList
+	corrections map[string]string
+	phonetics   map[string]wordList
+	mutex       sync.RWMutex
 }
 
 // Check finds correct spell suggestions for a word.
 func (sc *DefaultSpellChecker) Check(word string) []string {
+	scored := sc.CheckScored(word)
+
+	suggestions := make([]string, len(scored))
+	for i, s := range scored {
+		suggestions[i] = s.Word
+	}
+
+	return suggestions
+}
+
+// CheckScored finds correct spell suggestions for a word along with their
+// confidences. The confidence of each suggestion is its frequency relative to
+// the other suggestions. An unknown word counts as a suggestion seen once, so
+// rare neighbours of an unknown word get a low confidence. An explicit
+// correction of the word takes priority over the other suggestions.
+func (sc *DefaultSpellChecker) CheckScored(word string) []Suggestion {
+	if sc.Phonetic {
+		sc.ensurePhonetics()
+	}
+
 	sc.mutex.RLock()
 	defer sc.mutex.RUnlock()
 
+	if correction, ok := sc.corrections[word]; ok {
+		return []Suggestion{{Word: correction, Confidence: 1}}
+	}
+
 	length := utf8.RuneCountInString(word)
-	if sc.lexicon == nil || length < 2 {
-		return []string{word}
+	if length < 2 {
+		return []Suggestion{{Word: word, Confidence: 1}}
 	}
 
-	suggestions := []string{}
+	count := sc.lexicon[length].Count(word)
+	neighbours := wordList{}
 
-	if list, ok := sc.lexicon[length]; ok {
-		if list.Has(word) {
-			suggestions = append(suggestions, word)
+	for _, l := range []int{length, length - 1, length + 1} {
+		if l < 2 {
+			continue
 		}
-
-		for w := range list {
-			if Levenshtein(word, w) == 1 {
-				suggestions = append(suggestions, w)
+		for w, c := range sc.lexicon[l] {
+			if w != word && Levenshtein(word, w) == 1 {
+				neighbours[w] = c
 			}
 		}
 	}
 
-	if length > 2 {
-		if list, ok := sc.lexicon[length-1]; ok {
-			for w := range list {
-				if Levenshtein(word, w) == 1 {
-					suggestions = append(suggestions, w)
-				}
+	if sc.Phonetic {
+		for w := range sc.phonetics[PhoneticKey(word)] {
+			if w != word {
+				neighbours[w] = sc.lexicon[utf8.RuneCountInString(w)].Count(w)
 			}
 		}
 	}
 
-	if list, ok := sc.lexicon[length+1]; ok {
+	return scoreSuggestions(word, count, neighbours)
+}
+
+// ensurePhonetics builds the index of the words by their phonetic keys, if it
+// is not built yet. The index is then kept up to date by the changes to the
+// lexicon, or discarded by the bulk changes.
+func (sc *DefaultSpellChecker) ensurePhonetics() {
+	sc.mutex.RLock()
+	built := sc.phonetics != nil
+	sc.mutex.RUnlock()
+	if built {
+		return
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	if sc.phonetics != nil {
+		return
+	}
+
+	sc.phonetics = make(map[string]wordList)
+	for _, list := range sc.lexicon {
 		for w := range list {
-			if Levenshtein(word, w) == 1 {
-				suggestions = append(suggestions, w)
-			}
+			sc.indexPhonetic(w)
+		}
+	}
+}
+
+func (sc *DefaultSpellChecker) indexPhonetic(word string) {
+	key := PhoneticKey(word)
+	if _, ok := sc.phonetics[key]; !ok {
+		sc.phonetics[key] = make(wordList)
+	}
+	sc.phonetics[key][word] = 1
+}
+
+// unindexPhonetic removes a word from the phonetic index, if it is not in the
+// lexicon anymore.
+func (sc *DefaultSpellChecker) unindexPhonetic(word string) {
+	if sc.phonetics == nil || sc.lexicon[utf8.RuneCountInString(word)].Has(word) {
+		return
+	}
+
+	key := PhoneticKey(word)
+	if list, ok := sc.phonetics[key]; ok {
+		delete(list, word)
+		if len(list) == 0 {
+			delete(sc.phonetics, key)
+		}
+	}
+}
+
+// scoreSuggestions computes the confidences of the suggestions for a word,
+// given the frequency of the word itself and of its neighbours.
+func scoreSuggestions(word string, count int, neighbours wordList) []Suggestion {
+	words := make([]string, 0, len(neighbours))
+	total := 1
+	for w, c := range neighbours {
+		words = append(words, w)
+		total += c
+	}
+	sort.Slice(words, func(i, j int) bool {
+		ci, cj := neighbours[words[i]], neighbours[words[j]]
+		if ci != cj {
+			return ci > cj
 		}
+		return words[i] < words[j]
+	})
+
+	suggestions := make([]Suggestion, 0, len(words)+1)
+	if count > 0 {
+		total += count - 1
+		suggestions = append(suggestions, Suggestion{
+			Word:       word,
+			Confidence: float64(count) / float64(total),
+		})
+	}
+
+	for _, w := range words {
+		suggestions = append(suggestions, Suggestion{
+			Word:       w,
+			Confidence: float64(neighbours[w]) / float64(total),
+		})
 	}
 
 	if len(suggestions) == 0 {
-		suggestions = append(suggestions, word)
+		suggestions = append(suggestions, Suggestion{Word: word})
 	}
 
 	return suggestions
@@ -99,10 +311,107 @@ func (sc *DefaultSpellChecker) trainWord(word string) {
 	}
 
 	sc.lexicon[len].Add(word)
+	if sc.phonetics != nil {
+		sc.indexPhonetic(word)
+	}
 }
 
-// Load restores the state of the spell-checker from r.
-func (sc *DefaultSpellChecker) Load(r io.Reader) error {
+// Untrain removes the contributions of a list of words from the suggestion
+// model, i.e. reverts training with them.
+func (sc *DefaultSpellChecker) Untrain(words []string) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	for _, w := range words {
+		length := utf8.RuneCountInString(w)
+		if list, ok := sc.lexicon[length]; ok {
+			list.remove(w)
+			if len(list) == 0 {
+				delete(sc.lexicon, length)
+			}
+		}
+		sc.unindexPhonetic(w)
+	}
+}
+
+// Remove removes a word from the lexicon, regardless of how many times it was
+// trained.
+func (sc *DefaultSpellChecker) Remove(word string) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	length := utf8.RuneCountInString(word)
+	if list, ok := sc.lexicon[length]; ok {
+		delete(list, word)
+		if len(list) == 0 {
+			delete(sc.lexicon, length)
+		}
+	}
+	sc.unindexPhonetic(word)
+}
+
+// AddCorrection adds an explicit correction of a misspelling, which is
+// suggested with full confidence instead of the other suggestions.
+func (sc *DefaultSpellChecker) AddCorrection(misspelling, correction string) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	if sc.corrections == nil {
+		sc.corrections = make(map[string]string)
+	}
+
+	sc.corrections[misspelling] = correction
+}
+
+// RemoveCorrection removes the explicit correction of a misspelling.
+func (sc *DefaultSpellChecker) RemoveCorrection(misspelling string) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	delete(sc.corrections, misspelling)
+}
+
+// Corrections returns the explicit corrections, keyed by their misspellings.
+func (sc *DefaultSpellChecker) Corrections() map[string]string {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	corrections := make(map[string]string, len(sc.corrections))
+	for misspelling, correction := range sc.corrections {
+		corrections[misspelling] = correction
+	}
+
+	return corrections
+}
+
+// Prune removes the words trained less than minCount times from the lexicon,
+// e.g. the typos of a noisy corpus.
+func (sc *DefaultSpellChecker) Prune(minCount int) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	for length, list := range sc.lexicon {
+		list.prune(minCount)
+		if len(list) == 0 {
+			delete(sc.lexicon, length)
+		}
+	}
+	sc.phonetics = nil
+}
+
+// Merge adds the lexicon of another spell-checker to the spell-checker,
+// summing the word frequencies. The explicit corrections of the other
+// spell-checker replace the existing ones of the same misspellings.
+func (sc *DefaultSpellChecker) Merge(other *DefaultSpellChecker) {
+	corrections := other.Corrections()
+
+	other.mutex.RLock()
+	lexicon := make(map[int]wordList, len(other.lexicon))
+	for length, list := range other.lexicon {
+		lexicon[length] = list.clone()
+	}
+	other.mutex.RUnlock()
+
 	sc.mutex.Lock()
 	defer sc.mutex.Unlock()
 
@@ -110,7 +419,46 @@ func (sc *DefaultSpellChecker) Load(r io.Reader) error {
 		sc.lexicon = make(map[int]wordList)
 	}
 
-	return gob.NewDecoder(r).Decode(&sc.lexicon)
+	for length, list := range lexicon {
+		if _, ok := sc.lexicon[length]; !ok {
+			sc.lexicon[length] = make(wordList)
+		}
+		sc.lexicon[length].merge(list)
+	}
+	sc.phonetics = nil
+
+	if sc.corrections == nil {
+		sc.corrections = make(map[string]string)
+	}
+	for misspelling, correction := range corrections {
+		sc.corrections[misspelling] = correction
+	}
+}
+
+// Load restores the state of the spell-checker from r, replacing its current
+// state.
+func (sc *DefaultSpellChecker) Load(r io.Reader) error {
+	lexicon := make(map[int]wordList)
+	corrections := make(map[string]string)
+	legacy := map[int]legacyWordList{}
+	isLegacy, err := decodeGob(r, &lexicon, &legacy, &corrections)
+	if err != nil {
+		return err
+	}
+	if isLegacy {
+		for length, list := range legacy {
+			lexicon[length] = list.toWordList()
+		}
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	sc.lexicon = lexicon
+	sc.corrections = corrections
+	sc.phonetics = nil
+
+	return nil
 }
 
 // Save stores the state of the spell-checker into w.
@@ -122,5 +470,14 @@ func (sc *DefaultSpellChecker) Save(w io.Writer) error {
 		sc.lexicon = make(map[int]wordList)
 	}
 
-	return gob.NewEncoder(w).Encode(sc.lexicon)
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(sc.lexicon); err != nil {
+		return err
+	}
+
+	if len(sc.corrections) == 0 {
+		return nil
+	}
+
+	return enc.Encode(sc.corrections)
 }
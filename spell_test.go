@@ -1,6 +1,8 @@
 package ptpp_test
 
 import (
+	"bytes"
+	"encoding/gob"
 	"testing"
 
 	"gopkg.in/ptpp.v1"
@@ -27,3 +29,73 @@ func TestDefaultSpellChecker(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultSpellCheckerCheckScored(t *testing.T) {
+	var spellChecker ptpp.DefaultSpellChecker
+	spellChecker.Train([]string{"quality", "quality", "quality", "quantity"})
+
+	tests := []struct {
+		word string
+		want []ptpp.Suggestion
+	}{
+		{"quality", []ptpp.Suggestion{{Word: "quality", Confidence: 1}}},
+		{"qualitty", []ptpp.Suggestion{{Word: "quality", Confidence: 0.75}}},
+		{"quanlity", []ptpp.Suggestion{{Word: "quality", Confidence: 0.6}, {Word: "quantity", Confidence: 0.2}}},
+		{"unknown", []ptpp.Suggestion{{Word: "unknown", Confidence: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got := spellChecker.CheckScored(tt.word)
+			Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultSpellCheckerLoadLegacy(t *testing.T) {
+	var buf bytes.Buffer
+	legacy := map[int]map[string]bool{7: {"quality": true}}
+	if !NoError(t, gob.NewEncoder(&buf).Encode(legacy)) {
+		return
+	}
+
+	var spellChecker ptpp.DefaultSpellChecker
+	if NoError(t, spellChecker.Load(&buf)) {
+		Equal(t, []string{"quality"}, spellChecker.Check("qualitty"))
+	}
+}
+
+func TestDefaultSpellCheckerUntrain(t *testing.T) {
+	var spellChecker ptpp.DefaultSpellChecker
+	spellChecker.Train([]string{"quality", "quality", "quantity", "qualitty"})
+
+	spellChecker.Remove("qualitty")
+	Equal(t, []string{"quality"}, spellChecker.Check("qualitty"))
+
+	spellChecker.Untrain([]string{"quality"})
+	Equal(t, []ptpp.Suggestion{{Word: "quality", Confidence: 0.5}}, spellChecker.CheckScored("qualitty"))
+
+	spellChecker.Untrain([]string{"quality"})
+	ElementsMatch(t, []string{"quantity"}, spellChecker.Check("quanlity"))
+}
+
+func TestDefaultSpellCheckerCorrections(t *testing.T) {
+	var spellChecker ptpp.DefaultSpellChecker
+	spellChecker.Train([]string{"cheap", "chip"})
+	spellChecker.AddCorrection("cheep", "chip")
+
+	Equal(t, []ptpp.Suggestion{{Word: "chip", Confidence: 1}}, spellChecker.CheckScored("cheep"))
+
+	var buf bytes.Buffer
+	if !NoError(t, spellChecker.Save(&buf)) {
+		return
+	}
+
+	var loaded ptpp.DefaultSpellChecker
+	if NoError(t, loaded.Load(&buf)) {
+		Equal(t, map[string]string{"cheep": "chip"}, loaded.Corrections())
+		Equal(t, []string{"chip"}, loaded.Check("cheep"))
+	}
+
+	loaded.RemoveCorrection("cheep")
+	Equal(t, []string{"cheap"}, loaded.Check("cheep"))
+}
@@ -0,0 +1,104 @@
+package ptpp
+
+import "strings"
+
+// TokenType is the type of a token.
+type TokenType int
+
+const (
+	// WordToken is a word or a number.
+	WordToken TokenType = iota
+
+	// DateToken is a date in the YYYY-MM-DD form.
+	DateToken
+
+	// EmailToken is an email address.
+	EmailToken
+
+	// URLToken is a web address.
+	URLToken
+
+	// PhoneToken is a phone number.
+	PhoneToken
+
+	// HashtagToken is a hashtag, including its leading '#'.
+	HashtagToken
+)
+
+var tokenTypeNames = []string{"word", "date", "email", "url", "phone", "hashtag"}
+
+func (t TokenType) String() string {
+	if int(t) < len(tokenTypeNames) {
+		return tokenTypeNames[t]
+	}
+	return "unknown"
+}
+
+// TokenStatus is the spell-checking status of a token.
+type TokenStatus int
+
+const (
+	// Unchanged denotes a token which is kept as is, either because it is
+	// correctly spelled or because it is not a word.
+	Unchanged TokenStatus = iota
+
+	// Corrected denotes a word which is replaced by a spell suggestion.
+	Corrected
+
+	// Unknown denotes a word which is kept as is, because it is not known to
+	// the spell-checker and no suggestion was confident enough.
+	Unknown
+)
+
+var tokenStatusNames = []string{"unchanged", "corrected", "unknown"}
+
+func (s TokenStatus) String() string {
+	if int(s) < len(tokenStatusNames) {
+		return tokenStatusNames[s]
+	}
+	return "invalid"
+}
+
+// Token is a unit of the processed text.
+type Token struct {
+
+	// Text is the normalized, and possibly corrected, text of the token.
+	Text string
+
+	// Type is the type of the token.
+	Type TokenType
+
+	// Status is the spell-checking status of the token.
+	Status TokenStatus
+
+	// Language is the language of the token.
+	Language Language
+
+	// Confidence is the confidence of the spell-checker in Text. It is zero
+	// for the tokens which are not spell-checked.
+	Confidence float64
+
+	// fixed denotes a token produced by a rule, which is not spell-checked.
+	fixed bool
+}
+
+// Phrase is a phrase extracted from the processed text.
+type Phrase struct {
+
+	// Tokens are the tokens of the phrase. Tokens other than words always
+	// form a phrase on their own.
+	Tokens []Token
+
+	// Synonyms are the alternatives of the phrase found by the Expander of the
+	// processor, if any.
+	Synonyms []string
+}
+
+// String returns the text of the phrase.
+func (ph Phrase) String() string {
+	texts := make([]string, len(ph.Tokens))
+	for i, tok := range ph.Tokens {
+		texts[i] = tok.Text
+	}
+	return strings.Join(texts, " ")
+}
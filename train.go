@@ -0,0 +1,92 @@
+package ptpp
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// TrainParallel trains the preprocessing model with the phrases read from r,
+// one phrase per line, like TrainReader. The phrases are trained concurrently
+// into the given number of shards, which are merged into the model at the end.
+// If shards is not positive, runtime.GOMAXPROCS(0) shards are used. If reading
+// fails, the model is not changed.
+//
+// Sharding requires DefaultSpellChecker and DefaultSemanticMatcher, also for
+// the language models. Other components are trained sequentially, as by
+// TrainReader, so the phrases read before a read error stay trained.
+func (p *Processor) TrainParallel(r io.Reader, shards int) error {
+	m := p.ensureFields()
+	if !isMergeable(m) {
+		return p.TrainReader(r)
+	}
+
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+
+	batches := make(chan []string, shards)
+	processors := make([]*Processor, shards)
+	var wg sync.WaitGroup
+
+	for i := range processors {
+		processors[i] = p.newShard(m)
+
+		wg.Add(1)
+		go func(shard *Processor) {
+			defer wg.Done()
+			for batch := range batches {
+				shard.Train(batch)
+			}
+		}(processors[i])
+	}
+
+	err := readPhraseBatches(r, func(batch []string) {
+		batches <- batch
+	})
+	close(batches)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	for _, shard := range processors {
+		if err := p.Merge(shard); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newShard creates an empty processor which tokenizes like the processor, so
+// that it can be trained separately and merged into the processor.
+func (p *Processor) newShard(m models) *Processor {
+	languages := make(map[Language]LanguageModel, len(m.languages))
+	for language := range m.languages {
+		languages[language] = LanguageModel{}
+	}
+
+	return &Processor{
+		Languages:         languages,
+		Rules:             m.rules,
+		ConvertNumbers:    p.ConvertNumbers,
+		RecognizeDates:    p.RecognizeDates,
+		DateCalendar:      p.DateCalendar,
+		RecognizeEntities: p.RecognizeEntities,
+		SplitLanguages:    p.SplitLanguages,
+	}
+}
+
+// isMergeable checks whether trained shards can be merged into the models.
+func isMergeable(m models) bool {
+	for _, lm := range m.all() {
+		_, isDefaultSpellChecker := lm.spellChecker.(*DefaultSpellChecker)
+		_, isDefaultSemanticMatcher := lm.semanticMatcher.(*DefaultSemanticMatcher)
+		if !isDefaultSpellChecker || !isDefaultSemanticMatcher {
+			return false
+		}
+	}
+	return true
+}
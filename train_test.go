@@ -0,0 +1,59 @@
+package ptpp_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"gopkg.in/ptpp.v1"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestProcessorTrainParallel(t *testing.T) {
+	phrases := []string{}
+	for i := 0; i < 5000; i++ {
+		phrases = append(phrases, "bass guitar", "spanish rosetta stone", fmt.Sprintf("model %d", i%7))
+	}
+	corpus := strings.Join(phrases, "\n")
+
+	var want ptpp.Processor
+	if !NoError(t, want.TrainReader(strings.NewReader(corpus))) {
+		return
+	}
+
+	for _, shards := range []int{0, 1, 4} {
+		t.Run(fmt.Sprint(shards), func(t *testing.T) {
+			var got ptpp.Processor
+			if !NoError(t, got.TrainParallel(strings.NewReader(corpus), shards)) {
+				return
+			}
+
+			Equal(t, want.Training().Phrases, got.Training().Phrases)
+			for _, word := range []string{"base", "guitarr", "rosseta", "stone", "modal"} {
+				Equal(t,
+					want.SpellChecker.(ptpp.ScoringSpellChecker).CheckScored(word),
+					got.SpellChecker.(ptpp.ScoringSpellChecker).CheckScored(word),
+				)
+			}
+			for _, input := range []string{"electric base guitarr", "spannish rosetta stone"} {
+				wantPhrases, _ := want.Process(strings.NewReader(input))
+				gotPhrases, _ := got.Process(strings.NewReader(input))
+				Equal(t, wantPhrases, gotPhrases)
+			}
+		})
+	}
+
+	var failed ptpp.Processor
+	errRead := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader(corpus), iotest.ErrReader(errRead))
+	Equal(t, errRead, failed.TrainParallel(r, 4))
+	Equal(t, int64(0), failed.Training().Phrases)
+
+	custom := ptpp.Processor{SpellChecker: staticSpellChecker{}}
+	NoError(t, custom.TrainParallel(strings.NewReader(corpus), 4))
+	Equal(t, int64(len(phrases)), custom.Training().Phrases)
+}